@@ -0,0 +1,141 @@
+// Package authz defines the named permissions used across the API and the roles that grant them.
+// It holds no state and does no I/O; services resolve a user's role and ask this package what it allows.
+package authz
+
+// Permission names one action, e.g. "booking:confirm".
+type Permission string
+
+// Account-wide permissions, granted by the user's account role (Users.Role)
+const (
+	StadiumOwn    Permission = "stadium:own"
+	BookingCreate Permission = "booking:create"
+	APIKeyManage  Permission = "apikey:manage"
+
+	// Platform moderation, held by admins
+	UserModerate    Permission = "user:moderate"
+	ListingModerate Permission = "listing:moderate"
+	BookingModerate Permission = "booking:moderate"
+	AuditView       Permission = "audit:view"
+	SystemView      Permission = "system:view"
+)
+
+// Stadium-scoped permissions, held on one stadium through ownership or a staff membership
+const (
+	StadiumView     Permission = "stadium:view"
+	StadiumEdit     Permission = "stadium:edit"
+	StadiumDelete   Permission = "stadium:delete"
+	StadiumTransfer Permission = "stadium:transfer"
+	StaffManage     Permission = "staff:manage"
+	StadiumAudit    Permission = "stadium:audit"
+	ArenaManage     Permission = "arena:manage"
+	BookingView     Permission = "booking:view"
+	BookingConfirm  Permission = "booking:confirm"
+	BookingCancel   Permission = "booking:cancel"
+	BookingCheckIn  Permission = "booking:checkin"
+	BookingWalkIn   Permission = "booking:walkin"
+)
+
+// APIKeyScope limits what an API key can do. A key acts as the owner who created it, but only on
+// routes that accept one of its scopes; the owner's permissions still apply on top.
+type APIKeyScope string
+
+const (
+	ScopeAvailabilityRead APIKeyScope = "availability:read"
+	ScopeBookingsRead     APIKeyScope = "bookings:read"
+	ScopeBookingsWrite    APIKeyScope = "bookings:write"
+)
+
+var apiKeyScopes = []APIKeyScope{ScopeAvailabilityRead, ScopeBookingsRead, ScopeBookingsWrite}
+
+// Account roles
+const (
+	RoleOwner = "Owner"
+	RoleUser  = "User"
+	RoleAdmin = "Admin"
+)
+
+// Stadium roles. StadiumRoleOwner is implied by Stadiums.OwnerId and never stored as a membership.
+const (
+	StadiumRoleOwner     = "Owner"
+	StadiumRoleManager   = "Manager"
+	StadiumRoleFrontDesk = "FrontDesk"
+	StadiumRoleViewer    = "Viewer"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {StadiumOwn, BookingCreate, APIKeyManage},
+	RoleUser:  {BookingCreate},
+	RoleAdmin: {UserModerate, ListingModerate, BookingModerate, AuditView, SystemView},
+}
+
+var stadiumRolePermissions = map[string][]Permission{
+	StadiumRoleOwner: {
+		StadiumView, StadiumEdit, StadiumDelete, StadiumTransfer, StaffManage, StadiumAudit, ArenaManage,
+		BookingView, BookingConfirm, BookingCancel, BookingCheckIn, BookingWalkIn,
+	},
+	StadiumRoleManager: {
+		StadiumView, ArenaManage, BookingView, BookingConfirm, BookingCancel, BookingCheckIn, BookingWalkIn,
+	},
+	StadiumRoleFrontDesk: {
+		StadiumView, BookingView, BookingConfirm, BookingCheckIn, BookingWalkIn,
+	},
+	StadiumRoleViewer: {
+		StadiumView, BookingView,
+	},
+}
+
+// Roles a user may pick for themselves at signup. Admins are promoted by an existing admin or by hand.
+var signupRoles = []string{RoleOwner, RoleUser}
+
+// Stadium roles an owner may hand out to staff
+var memberRoles = []string{StadiumRoleManager, StadiumRoleFrontDesk, StadiumRoleViewer}
+
+// RoleHas reports whether the account role grants the permission
+func RoleHas(role string, permission Permission) bool {
+	return contains(rolePermissions[role], permission)
+}
+
+// StadiumRoleHas reports whether the stadium role grants the permission on that stadium
+func StadiumRoleHas(role string, permission Permission) bool {
+	return contains(stadiumRolePermissions[role], permission)
+}
+
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func IsSignupRole(role string) bool {
+	return containsRole(signupRoles, role)
+}
+
+func IsMemberRole(role string) bool {
+	return containsRole(memberRoles, role)
+}
+
+func IsAPIKeyScope(scope string) bool {
+	for _, s := range apiKeyScopes {
+		if string(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+// Package breached checks passwords against a local list of known-breached passwords, stored as
+// SHA-1 hashes in the format of the Pwned Passwords downloads. Nothing is sent over the network.
+//
+// Two layouts are supported:
+//   - a single file with one "HASH" or "HASH:COUNT" line per password, loaded into memory. Fine for
+//     lists of a few million entries, e.g. the most common passwords.
+//   - a directory of range files named after the first five hex digits of the hash ("5BAA6.txt"),
+//     each holding "SUFFIX:COUNT" lines for the remaining 35 digits. Only the one file a password
+//     hashes into is read, so the full corpus can stay on disk.
+package breached
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	hashLength   = sha1.Size * 2
+	prefixLength = 5
+)
+
+// List reports whether a password appears in a breach corpus
+type List interface {
+	Contains(password string) (bool, error)
+}
+
+// Load opens the list at path. Entries seen fewer than minCount times are ignored; lines without a
+// count count once.
+func Load(path string, minCount int) (List, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &rangeDir{dir: path, minCount: minCount}, nil
+	}
+	return loadHashSet(path, minCount)
+}
+
+// Hash returns the uppercase hex SHA-1 of the password, as used in the lists
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+type hashSet map[[sha1.Size]byte]struct{}
+
+func loadHashSet(path string, minCount int) (hashSet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	set := make(hashSet)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		hash, count, ok := parseLine(scanner.Text())
+		if !ok {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("%s:%d: expected HASH or HASH:COUNT", path, line)
+		}
+		if len(hash) != hashLength {
+			return nil, fmt.Errorf("%s:%d: expected a %d-digit SHA-1 hash", path, line, hashLength)
+		}
+		if count < minCount {
+			continue
+		}
+
+		var key [sha1.Size]byte
+		if _, err := hex.Decode(key[:], []byte(hash)); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		set[key] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+func (s hashSet) Contains(password string) (bool, error) {
+	_, found := s[sha1.Sum([]byte(password))]
+	return found, nil
+}
+
+type rangeDir struct {
+	dir      string
+	minCount int
+}
+
+func (d *rangeDir) Contains(password string) (bool, error) {
+	hash := Hash(password)
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	file, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, count, ok := parseLine(scanner.Text())
+		if ok && entry == suffix {
+			return count >= d.minCount, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// parseLine splits "HASH:COUNT" or "HASH", upper-casing the hash
+func parseLine(line string) (string, int, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", 0, false
+	}
+
+	hash, countText, hasCount := strings.Cut(line, ":")
+	count := 1
+	if hasCount {
+		n, err := strconv.Atoi(strings.TrimSpace(countText))
+		if err != nil {
+			return "", 0, false
+		}
+		count = n
+	}
+	return strings.ToUpper(strings.TrimSpace(hash)), count, true
+}
@@ -0,0 +1,140 @@
+package breached
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if got, want := Hash("password"), "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"; got != want {
+		t.Errorf("Hash(password) = %s, want %s", got, want)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantHash  string
+		wantCount int
+		wantOK    bool
+	}{
+		{"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", 1, true},
+		{"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:42", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", 42, true},
+		{"  1E4C9B93F3F0682250B6CF8331B7EE68FD8 : 7 \r", "1E4C9B93F3F0682250B6CF8331B7EE68FD8", 7, true},
+		{"", "", 0, false},
+		{"   ", "", 0, false},
+		{"ABC:many", "", 0, false},
+	}
+	for _, tt := range tests {
+		hash, count, ok := parseLine(tt.line)
+		if hash != tt.wantHash || count != tt.wantCount || ok != tt.wantOK {
+			t.Errorf("parseLine(%q) = %q, %d, %v, want %q, %d, %v", tt.line, hash, count, ok, tt.wantHash, tt.wantCount, tt.wantOK)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "top.txt")
+	writeFile(t, path,
+		Hash("password")+":100",
+		"",
+		strings.ToLower(Hash("letmein")),
+		Hash("rare-one")+":2",
+	)
+
+	tests := []struct {
+		minCount int
+		password string
+		want     bool
+	}{
+		{1, "password", true},
+		{1, "letmein", true},
+		{1, "rare-one", true},
+		{1, "not-in-the-list", false},
+		{3, "password", true},
+		{3, "letmein", false}, // no count means seen once
+		{3, "rare-one", false},
+	}
+	for _, tt := range tests {
+		list, err := Load(path, tt.minCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := list.Contains(tt.password)
+		if err != nil || got != tt.want {
+			t.Errorf("minCount %d: Contains(%q) = %v, %v, want %v", tt.minCount, tt.password, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		line    string
+		wantErr string
+	}{
+		{"bad count", Hash("password") + ":lots", "top.txt:2: expected HASH or HASH:COUNT"},
+		{"short hash", "5BAA61E4:3", "top.txt:2: expected a 40-digit SHA-1 hash"},
+		{"not hex", strings.Repeat("Z", 40), "top.txt:2: encoding/hex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "top.txt")
+			writeFile(t, path, Hash("fine"), tt.line)
+			_, err := Load(path, 1)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.txt"), 1); !os.IsNotExist(err) {
+		t.Errorf("Load of a missing file = %v, want a not-exist error", err)
+	}
+}
+
+func TestLoadRangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	hash := Hash("password")
+	writeFile(t, filepath.Join(dir, hash[:prefixLength]+".txt"),
+		"0018A45C4D1DEF81644B54AB7F969B88D65:1",
+		strings.ToLower(hash[prefixLength:])+":5",
+		"not a line we can parse",
+	)
+	rare := Hash("rare-one")
+	writeFile(t, filepath.Join(dir, rare[:prefixLength]+".txt"), rare[prefixLength:]+":1")
+
+	tests := []struct {
+		minCount int
+		password string
+		want     bool
+	}{
+		{1, "password", true},
+		{5, "password", true},
+		{6, "password", false},
+		{1, "rare-one", true},
+		{2, "rare-one", false},
+		{1, "no-range-file-for-this", false},
+	}
+	for _, tt := range tests {
+		list, err := Load(dir, tt.minCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := list.Contains(tt.password)
+		if err != nil || got != tt.want {
+			t.Errorf("minCount %d: Contains(%q) = %v, %v, want %v", tt.minCount, tt.password, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package cache is a small key/value cache with per-entry expiry. The Store is pluggable: process
+// memory for a single instance, or Redis when several instances must see the same entries and,
+// more importantly, the same invalidations.
+package cache
+
+import (
+	"BookMyArena/backend/config"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// Store holds opaque values. Implementations must be safe for concurrent use. A store that can't
+// be reached should behave like an empty cache rather than fail the request.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(keys ...string)
+}
+
+var store Store = NoopStore{}
+
+// Init picks the store from settings.Backend: "memory", "redis" or "none". How long entries are
+// kept is up to the callers; settings.TTL is their default.
+func Init(settings config.CacheSettings) error {
+	switch settings.Backend {
+	case "memory":
+		store = NewMemoryStore(defaultMaxEntries)
+	case "redis":
+		store = NewRedisStore(settings.RedisAddr, settings.RedisPassword, settings.RedisDB)
+	case "none":
+		store = NoopStore{}
+	default:
+		return fmt.Errorf("unknown cache backend %q", settings.Backend)
+	}
+
+	log.Printf("Cache backend: %s (TTL %s)\n", settings.Backend, settings.TTL)
+	return nil
+}
+
+// Close releases the store's connections, if it holds any
+func Close() error {
+	if closer, ok := store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SetStore replaces the active store
+func SetStore(s Store) {
+	store = s
+}
+
+func Get(key string) ([]byte, bool) {
+	return store.Get(key)
+}
+
+func Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	store.Set(key, value, ttl)
+}
+
+func Delete(keys ...string) {
+	if len(keys) > 0 {
+		store.Delete(keys...)
+	}
+}
+
+// GetJSON decodes a cached value into v. A value that no longer decodes counts as a miss.
+func GetJSON(key string, v interface{}) bool {
+	data, ok := Get(key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func SetJSON(key string, v interface{}, ttl time.Duration) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Println("Error encoding cache entry:", err)
+		return
+	}
+	Set(key, data, ttl)
+}
+
+// NoopStore caches nothing
+type NoopStore struct{}
+
+func (NoopStore) Get(string) ([]byte, bool)         { return nil, false }
+func (NoopStore) Set(string, []byte, time.Duration) {}
+func (NoopStore) Delete(...string)                  {}
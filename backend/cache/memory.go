@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultMaxEntries = 100000
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore keeps entries in this process. Each instance has its own copy, so with several
+// instances an invalidation only reaches the one that made it; keep TTLs short or use Redis.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	entries    map[string]memoryEntry
+	maxEntries int
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), maxEntries: maxEntries}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	entry, ok := s.entries[key]
+	s.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+func (s *MemoryStore) Delete(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+}
+
+// evict drops expired entries, and if that isn't enough, an arbitrary tenth of the rest.
+// Called with s.mu held.
+func (s *MemoryStore) evict() {
+	now := time.Now()
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	excess := len(s.entries) - s.maxEntries*9/10
+	for key := range s.entries {
+		if excess <= 0 {
+			break
+		}
+		delete(s.entries, key)
+		excess--
+	}
+}
@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	redisKeyPrefix   = "bookmyarena:"
+	redisPoolSize    = 16
+	redisDialTimeout = 2 * time.Second
+	redisIOTimeout   = time.Second
+)
+
+// RedisStore shares entries between instances. It speaks just enough of the Redis protocol for
+// GET, SET with expiry and DEL. Connection errors are logged and treated as cache misses.
+type RedisStore struct {
+	addr     string
+	password string
+	db       int
+	pool     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisStore(addr, password string, db int) *RedisStore {
+	return &RedisStore{addr: addr, password: password, db: db, pool: make(chan *redisConn, redisPoolSize)}
+}
+
+func (s *RedisStore) Get(key string) ([]byte, bool) {
+	value, err := s.do("GET", redisKeyPrefix+key)
+	if err != nil {
+		log.Println("Redis GET failed:", err)
+		return nil, false
+	}
+	data, ok := value.([]byte)
+	return data, ok
+}
+
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) {
+	millis := ttl.Milliseconds()
+	if millis < 1 {
+		millis = 1
+	}
+	if _, err := s.do("SET", redisKeyPrefix+key, string(value), "PX", strconv.FormatInt(millis, 10)); err != nil {
+		log.Println("Redis SET failed:", err)
+	}
+}
+
+func (s *RedisStore) Delete(keys ...string) {
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, redisKeyPrefix+key)
+	}
+	if _, err := s.do(args...); err != nil {
+		log.Println("Redis DEL failed:", err)
+	}
+}
+
+// Close closes the idle pooled connections. Call it once nothing uses the store any more.
+func (s *RedisStore) Close() error {
+	for {
+		select {
+		case c := <-s.pool:
+			c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do runs one command on a pooled connection. A connection that errors is closed, not returned.
+func (s *RedisStore) do(args ...string) (interface{}, error) {
+	c, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.command(args...)
+	if err != nil {
+		var replyErr redisError
+		if !errors.As(err, &replyErr) {
+			c.conn.Close()
+			return nil, err
+		}
+	}
+
+	select {
+	case s.pool <- c:
+	default:
+		c.conn.Close()
+	}
+	return reply, err
+}
+
+func (s *RedisStore) get() (*redisConn, error) {
+	select {
+	case c := <-s.pool:
+		return c, nil
+	default:
+	}
+
+	conn, err := net.DialTimeout("tcp", s.addr, redisDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	if s.password != "" {
+		if _, err := c.command("AUTH", s.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if s.db != 0 {
+		if _, err := c.command("SELECT", strconv.Itoa(s.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (c *redisConn) command(args ...string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(redisIOTimeout))
+
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+// readReply parses one reply: simple strings, errors, integers and bulk strings (nil for a missing key)
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	body := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+}
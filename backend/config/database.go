@@ -1,33 +1,124 @@
 package config
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
-	"os"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/microsoft/go-mssqldb"
 )
 
 var DB *sql.DB
 
-func InitDB() {
-	connectionString := os.Getenv("DB_CONNECTION_STRING")
-	if connectionString == "" {
-		// Default connection string for SQL Server
-		connectionString = "server=localhost,1433;database=BookMyArena;trusted_connection=yes;encrypt=disable"
-	}
+// SchemaVersion is the newest migration in database_init.sql this build needs. Bump it together
+// with the INSERT INTO SchemaVersions at the end of a new migration.
+const SchemaVersion = 1
+
+const (
+	dbPingTimeout      = 5 * time.Second
+	dbRetryMinInterval = time.Second
+	dbRetryMaxInterval = 30 * time.Second
+)
+
+// ErrSchemaOutdated means database_init.sql hasn't been run since this build's migrations were added
+var ErrSchemaOutdated = errors.New("database schema is out of date, run database_init.sql")
 
+// dbAvailable records whether the last ping reached the database
+var dbAvailable atomic.Bool
+
+// InitDB opens the connection pool. An unreachable database doesn't stop the server: it starts
+// degraded, requests that need the database fail and /readyz reports it, until ReconnectDB gets
+// through. Only a connection string the driver can't use at all is fatal.
+func InitDB(settings DatabaseSettings) {
 	var err error
-	DB, err = sql.Open("sqlserver", connectionString)
+	DB, err = sql.Open("sqlserver", settings.ConnectionString)
 	if err != nil {
 		log.Fatal("Error connecting to database:", err)
 	}
 
-	if err = DB.Ping(); err != nil {
-		log.Fatal("Error pinging database:", err)
+	if err = PingDB(context.Background()); err != nil {
+		log.Println("Database unavailable, starting in degraded mode:", err)
+		return
 	}
 
 	log.Println("Database connection established successfully")
+	checkSchemaVersion()
+}
+
+// PingDB checks that the database answers, recording the result for DBAvailable
+func PingDB(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, dbPingTimeout)
+	defer cancel()
+
+	err := DB.PingContext(ctx)
+	dbAvailable.Store(err == nil)
+	return err
+}
+
+// DBAvailable reports whether the database answered the last ping
+func DBAvailable() bool {
+	return dbAvailable.Load()
+}
+
+// ReconnectDB pings with exponential backoff until the database answers or done is closed
+func ReconnectDB(done <-chan struct{}) {
+	interval := dbRetryMinInterval
+	for !DBAvailable() {
+		select {
+		case <-done:
+			return
+		case <-time.After(interval):
+		}
+
+		if err := PingDB(context.Background()); err != nil {
+			interval = minDuration(interval*2, dbRetryMaxInterval)
+			log.Printf("Database still unavailable, retrying in %s: %v\n", interval, err)
+			continue
+		}
+		log.Println("Database connection established, leaving degraded mode")
+		checkSchemaVersion()
+	}
+}
+
+// AppliedSchemaVersion returns the newest migration recorded in SchemaVersions
+func AppliedSchemaVersion(ctx context.Context) (int, error) {
+	var version sql.NullInt64
+	err := DB.QueryRowContext(ctx,
+		"IF OBJECT_ID('SchemaVersions', 'U') IS NULL SELECT CAST(NULL AS INT) ELSE SELECT MAX(Version) FROM SchemaVersions",
+	).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// CheckSchema reports an error unless the database has every migration this build needs
+func CheckSchema(ctx context.Context) error {
+	applied, err := AppliedSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if applied < SchemaVersion {
+		return ErrSchemaOutdated
+	}
+	return nil
+}
+
+// checkSchemaVersion warns at startup; /readyz keeps failing until the migrations are applied
+func checkSchemaVersion() {
+	if err := CheckSchema(context.Background()); err != nil {
+		log.Println("Warning:", err)
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
 }
 
 func CloseDB() {
@@ -11,7 +11,7 @@ CREATE TABLE Users (
     FullName NVARCHAR(100) NOT NULL,
     Email NVARCHAR(255) UNIQUE NOT NULL,
     PasswordHash NVARCHAR(255) NOT NULL,
-    Role NVARCHAR(50) NOT NULL CHECK (Role IN ('Owner', 'User')),
+    Role NVARCHAR(50) NOT NULL,
     CreatedAt DATETIME NOT NULL DEFAULT GETDATE()
 );
 GO
@@ -23,11 +23,33 @@ CREATE TABLE Stadiums (
     OwnerId INT NOT NULL,
     Name NVARCHAR(100) NOT NULL,
     Location NVARCHAR(255) NOT NULL,
+    Description NVARCHAR(1000) NOT NULL DEFAULT '',
     CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
     FOREIGN KEY (OwnerId) REFERENCES Users(UserId) ON DELETE CASCADE
 );
 GO
 
+-- Sport Types Table (catalog with parent/child hierarchy, e.g. Football > Futsal)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='SportTypes' AND xtype='U')
+CREATE TABLE SportTypes (
+    SportTypeId INT PRIMARY KEY IDENTITY(1,1),
+    Name NVARCHAR(50) UNIQUE NOT NULL,
+    ParentId INT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (ParentId) REFERENCES SportTypes(SportTypeId)
+);
+GO
+
+-- Sport Type Aliases Table (alternative spellings that resolve to a catalog entry)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='SportTypeAliases' AND xtype='U')
+CREATE TABLE SportTypeAliases (
+    AliasId INT PRIMARY KEY IDENTITY(1,1),
+    SportTypeId INT NOT NULL,
+    Alias NVARCHAR(50) UNIQUE NOT NULL,
+    FOREIGN KEY (SportTypeId) REFERENCES SportTypes(SportTypeId) ON DELETE CASCADE
+);
+GO
+
 -- Arenas Table
 IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Arenas' AND xtype='U')
 CREATE TABLE Arenas (
@@ -35,11 +57,14 @@ CREATE TABLE Arenas (
     StadiumId INT NOT NULL,
     Name NVARCHAR(100) NOT NULL,
     SportType NVARCHAR(50) NOT NULL,
+    SportTypeId INT NOT NULL,
+    Description NVARCHAR(1000) NOT NULL DEFAULT '',
     Capacity INT NOT NULL,
     SlotDuration INT NOT NULL,
     Price DECIMAL(10,2) NOT NULL,
     CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
-    FOREIGN KEY (StadiumId) REFERENCES Stadiums(StadiumId) ON DELETE CASCADE
+    FOREIGN KEY (StadiumId) REFERENCES Stadiums(StadiumId) ON DELETE CASCADE,
+    FOREIGN KEY (SportTypeId) REFERENCES SportTypes(SportTypeId)
 );
 GO
 
@@ -79,3 +104,524 @@ CREATE INDEX IX_Sessions_Token ON Sessions(Token);
 CREATE INDEX IX_Sessions_ExpiresAt ON Sessions(ExpiresAt);
 GO
 
+
+-- Sport type catalog seed data
+INSERT INTO SportTypes (Name)
+SELECT v.Name
+FROM (VALUES ('Football'), ('Cricket'), ('Hockey'), ('Tennis'), ('Padel'), ('Badminton'),
+             ('Squash'), ('Table Tennis'), ('Basketball'), ('Volleyball')) v(Name)
+WHERE NOT EXISTS (SELECT 1 FROM SportTypes st WHERE st.Name = v.Name);
+
+INSERT INTO SportTypes (Name, ParentId)
+SELECT v.Name, p.SportTypeId
+FROM (VALUES ('Futsal', 'Football'), ('Indoor Cricket', 'Cricket'), ('Beach Volleyball', 'Volleyball')) v(Name, Parent)
+INNER JOIN SportTypes p ON p.Name = v.Parent
+WHERE NOT EXISTS (SELECT 1 FROM SportTypes st WHERE st.Name = v.Name);
+
+INSERT INTO SportTypeAliases (SportTypeId, Alias)
+SELECT st.SportTypeId, v.Alias
+FROM (VALUES ('Soccer', 'Football'), ('Footy', 'Football'), ('5-a-side', 'Futsal'), ('Five-a-side', 'Futsal'),
+             ('Indoor Football', 'Futsal'), ('Box Cricket', 'Indoor Cricket'), ('Field Hockey', 'Hockey'),
+             ('Lawn Tennis', 'Tennis'), ('Padel Tennis', 'Padel'), ('Ping Pong', 'Table Tennis'),
+             ('Hoops', 'Basketball')) v(Alias, Name)
+INNER JOIN SportTypes st ON st.Name = v.Name
+WHERE NOT EXISTS (SELECT 1 FROM SportTypeAliases sa WHERE sa.Alias = v.Alias);
+GO
+
+-- Resolves a search term (canonical name or alias) to the matching sport type and all of its descendants
+CREATE OR ALTER FUNCTION dbo.MatchSportTypes(@Term NVARCHAR(50))
+RETURNS TABLE
+AS
+RETURN (
+    WITH Matched AS (
+        SELECT SportTypeId FROM SportTypes WHERE Name = LTRIM(RTRIM(@Term))
+        UNION
+        SELECT SportTypeId FROM SportTypeAliases WHERE Alias = LTRIM(RTRIM(@Term))
+    ),
+    Descendants AS (
+        SELECT SportTypeId FROM Matched
+        UNION ALL
+        SELECT st.SportTypeId FROM SportTypes st INNER JOIN Descendants d ON st.ParentId = d.SportTypeId
+    )
+    SELECT DISTINCT SportTypeId FROM Descendants
+);
+GO
+
+-- Migration: map legacy free-text Arenas.SportType values onto the catalog
+IF COL_LENGTH('Arenas', 'SportTypeId') IS NULL
+    ALTER TABLE Arenas ADD SportTypeId INT NULL;
+GO
+
+-- Unrecognised legacy values become top-level catalog entries so no arena is left unmapped
+INSERT INTO SportTypes (Name)
+SELECT DISTINCT LTRIM(RTRIM(a.SportType))
+FROM Arenas a
+WHERE a.SportTypeId IS NULL
+  AND NOT EXISTS (SELECT 1 FROM SportTypes st WHERE st.Name = LTRIM(RTRIM(a.SportType)))
+  AND NOT EXISTS (SELECT 1 FROM SportTypeAliases sa WHERE sa.Alias = LTRIM(RTRIM(a.SportType)));
+
+UPDATE a
+SET a.SportTypeId = COALESCE(st.SportTypeId, sa.SportTypeId)
+FROM Arenas a
+LEFT JOIN SportTypes st ON st.Name = LTRIM(RTRIM(a.SportType))
+LEFT JOIN SportTypeAliases sa ON sa.Alias = LTRIM(RTRIM(a.SportType))
+WHERE a.SportTypeId IS NULL;
+
+-- Store the canonical name alongside the reference
+UPDATE a
+SET a.SportType = st.Name
+FROM Arenas a
+INNER JOIN SportTypes st ON a.SportTypeId = st.SportTypeId;
+GO
+
+-- Only while the column is still nullable: once the foreign key and index below exist, SQL Server
+-- refuses to alter it, and re-running this script is how upgrades are applied
+IF COLUMNPROPERTY(OBJECT_ID('Arenas'), 'SportTypeId', 'AllowsNull') = 1
+    ALTER TABLE Arenas ALTER COLUMN SportTypeId INT NOT NULL;
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.foreign_keys WHERE name = 'FK_Arenas_SportTypes')
+    AND NOT EXISTS (SELECT * FROM sys.foreign_key_columns fkc
+                    INNER JOIN sys.columns c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id
+                    WHERE fkc.parent_object_id = OBJECT_ID('Arenas') AND c.name = 'SportTypeId')
+    ALTER TABLE Arenas ADD CONSTRAINT FK_Arenas_SportTypes FOREIGN KEY (SportTypeId) REFERENCES SportTypes(SportTypeId);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Arenas_SportTypeId')
+    CREATE INDEX IX_Arenas_SportTypeId ON Arenas(SportTypeId);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_SportTypes_ParentId')
+    CREATE INDEX IX_SportTypes_ParentId ON SportTypes(ParentId);
+GO
+
+-- Migration: free-text descriptions on stadiums and arenas (covered by the search index)
+IF COL_LENGTH('Stadiums', 'Description') IS NULL
+    ALTER TABLE Stadiums ADD Description NVARCHAR(1000) NOT NULL DEFAULT '';
+IF COL_LENGTH('Arenas', 'Description') IS NULL
+    ALTER TABLE Arenas ADD Description NVARCHAR(1000) NOT NULL DEFAULT '';
+GO
+
+-- Saved Searches Table (users are alerted when a matching slot opens up)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='SavedSearches' AND xtype='U')
+CREATE TABLE SavedSearches (
+    SavedSearchId INT PRIMARY KEY IDENTITY(1,1),
+    UserId INT NOT NULL,
+    Name NVARCHAR(100) NOT NULL DEFAULT '',
+    SportType NVARCHAR(50) NOT NULL DEFAULT '',
+    Location NVARCHAR(255) NOT NULL DEFAULT '',
+    StadiumId INT NULL,
+    Duration INT NOT NULL DEFAULT 0,
+    WindowStart DATETIME NOT NULL,
+    WindowEnd DATETIME NOT NULL,
+    LastNotifiedAt DATETIME NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE
+);
+GO
+
+-- Notifications Table
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='Notifications' AND xtype='U')
+CREATE TABLE Notifications (
+    NotificationId INT PRIMARY KEY IDENTITY(1,1),
+    UserId INT NOT NULL,
+    Type NVARCHAR(50) NOT NULL,
+    Message NVARCHAR(500) NOT NULL,
+    Data NVARCHAR(MAX) NULL,
+    ReadAt DATETIME NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_SavedSearches_UserId')
+    CREATE INDEX IX_SavedSearches_UserId ON SavedSearches(UserId);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_SavedSearches_WindowEnd')
+    CREATE INDEX IX_SavedSearches_WindowEnd ON SavedSearches(WindowEnd);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Notifications_UserId_CreatedAt')
+    CREATE INDEX IX_Notifications_UserId_CreatedAt ON Notifications(UserId, CreatedAt);
+GO
+
+-- Stadium Transfers Table (ownership moves only once the receiving owner accepts)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='StadiumTransfers' AND xtype='U')
+CREATE TABLE StadiumTransfers (
+    TransferId INT PRIMARY KEY IDENTITY(1,1),
+    StadiumId INT NOT NULL,
+    FromOwnerId INT NOT NULL,
+    ToOwnerId INT NOT NULL,
+    Status NVARCHAR(50) NOT NULL DEFAULT 'Pending' CHECK (Status IN ('Pending', 'Accepted', 'Declined', 'Cancelled')),
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    RespondedAt DATETIME NULL,
+    FOREIGN KEY (StadiumId) REFERENCES Stadiums(StadiumId) ON DELETE CASCADE,
+    FOREIGN KEY (FromOwnerId) REFERENCES Users(UserId),
+    FOREIGN KEY (ToOwnerId) REFERENCES Users(UserId)
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_StadiumTransfers_StadiumId')
+    CREATE INDEX IX_StadiumTransfers_StadiumId ON StadiumTransfers(StadiumId);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_StadiumTransfers_ToOwnerId')
+    CREATE INDEX IX_StadiumTransfers_ToOwnerId ON StadiumTransfers(ToOwnerId);
+GO
+
+-- Stadium Members Table (staff accounts with a per-stadium role)
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='StadiumMembers' AND xtype='U')
+CREATE TABLE StadiumMembers (
+    MemberId INT PRIMARY KEY IDENTITY(1,1),
+    StadiumId INT NOT NULL,
+    UserId INT NOT NULL,
+    Role NVARCHAR(50) NOT NULL,
+    Status NVARCHAR(50) NOT NULL DEFAULT 'Invited' CHECK (Status IN ('Invited', 'Active')),
+    InvitedBy INT NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    AcceptedAt DATETIME NULL,
+    CONSTRAINT UQ_StadiumMembers_StadiumId_UserId UNIQUE (StadiumId, UserId),
+    FOREIGN KEY (StadiumId) REFERENCES Stadiums(StadiumId) ON DELETE CASCADE,
+    FOREIGN KEY (UserId) REFERENCES Users(UserId)
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_StadiumMembers_UserId')
+    CREATE INDEX IX_StadiumMembers_UserId ON StadiumMembers(UserId);
+GO
+
+-- Migration: front desk check-in and walk-in bookings
+IF COL_LENGTH('Bookings', 'CheckedInAt') IS NULL
+    ALTER TABLE Bookings ADD CheckedInAt DATETIME NULL;
+IF COL_LENGTH('Bookings', 'WalkInName') IS NULL
+    ALTER TABLE Bookings ADD WalkInName NVARCHAR(100) NOT NULL DEFAULT '';
+GO
+
+-- Migration: roles and their permissions are defined in the application (backend/authz),
+-- so adding a role no longer needs a schema change. Drop the old role CHECK constraints.
+DECLARE @RoleCheckTable NVARCHAR(128), @RoleCheck NVARCHAR(128);
+DECLARE role_checks CURSOR LOCAL FAST_FORWARD FOR
+    SELECT OBJECT_NAME(cc.parent_object_id), cc.name
+    FROM sys.check_constraints cc
+    INNER JOIN sys.columns c ON c.object_id = cc.parent_object_id AND c.column_id = cc.parent_column_id
+    WHERE c.name = 'Role' AND cc.parent_object_id IN (OBJECT_ID('Users'), OBJECT_ID('StadiumMembers'));
+OPEN role_checks;
+FETCH NEXT FROM role_checks INTO @RoleCheckTable, @RoleCheck;
+WHILE @@FETCH_STATUS = 0
+BEGIN
+    EXEC('ALTER TABLE ' + @RoleCheckTable + ' DROP CONSTRAINT ' + @RoleCheck);
+    FETCH NEXT FROM role_checks INTO @RoleCheckTable, @RoleCheck;
+END
+CLOSE role_checks;
+DEALLOCATE role_checks;
+GO
+
+-- Migration: platform moderation (account suspension, unpublished listings, admin cancellations)
+IF COL_LENGTH('Users', 'Status') IS NULL
+    ALTER TABLE Users ADD Status NVARCHAR(50) NOT NULL DEFAULT 'Active' CHECK (Status IN ('Active', 'Suspended'));
+IF COL_LENGTH('Stadiums', 'IsPublished') IS NULL
+    ALTER TABLE Stadiums ADD IsPublished BIT NOT NULL DEFAULT 1;
+IF COL_LENGTH('Arenas', 'IsPublished') IS NULL
+    ALTER TABLE Arenas ADD IsPublished BIT NOT NULL DEFAULT 1;
+IF COL_LENGTH('Bookings', 'CancellationReason') IS NULL
+    ALTER TABLE Bookings ADD CancellationReason NVARCHAR(500) NOT NULL DEFAULT '';
+GO
+
+-- Audit Log Table (append-only record of privileged actions). ActorId has no foreign key
+-- so entries outlive the accounts that made them.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AuditLog' AND xtype='U')
+CREATE TABLE AuditLog (
+    AuditId INT PRIMARY KEY IDENTITY(1,1),
+    ActorId INT NULL,
+    Action NVARCHAR(100) NOT NULL,
+    EntityType NVARCHAR(50) NOT NULL,
+    EntityId INT NULL,
+    Details NVARCHAR(MAX) NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE()
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_AuditLog_Entity')
+    CREATE INDEX IX_AuditLog_Entity ON AuditLog(EntityType, EntityId);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_AuditLog_CreatedAt')
+    CREATE INDEX IX_AuditLog_CreatedAt ON AuditLog(CreatedAt);
+GO
+
+-- Migration: stadium review. New stadiums start as Draft and are listed only once an admin approves them;
+-- stadiums that were already live are grandfathered in as Approved.
+IF COL_LENGTH('Stadiums', 'Status') IS NULL
+BEGIN
+    ALTER TABLE Stadiums ADD Status NVARCHAR(50) NOT NULL DEFAULT 'Draft'
+        CHECK (Status IN ('Draft', 'PendingReview', 'Approved', 'Rejected'));
+    EXEC('UPDATE Stadiums SET Status = ''Approved''');
+END
+IF COL_LENGTH('Stadiums', 'ReviewNote') IS NULL
+    ALTER TABLE Stadiums ADD ReviewNote NVARCHAR(500) NOT NULL DEFAULT '';
+IF COL_LENGTH('Stadiums', 'SubmittedAt') IS NULL
+    ALTER TABLE Stadiums ADD SubmittedAt DATETIME NULL;
+IF COL_LENGTH('Stadiums', 'ReviewedAt') IS NULL
+    ALTER TABLE Stadiums ADD ReviewedAt DATETIME NULL;
+IF COL_LENGTH('Stadiums', 'ReviewedBy') IS NULL
+    ALTER TABLE Stadiums ADD ReviewedBy INT NULL;
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Stadiums_Status')
+    CREATE INDEX IX_Stadiums_Status ON Stadiums(Status);
+GO
+
+-- Migration: full audit trail. Every write records a field-level diff, the client IP and the stadium it
+-- belongs to so owners can read their own slice of the log. StadiumId has no foreign key for the same
+-- reason as ActorId: entries must outlive a deleted stadium.
+IF COL_LENGTH('AuditLog', 'Changes') IS NULL
+    ALTER TABLE AuditLog ADD Changes NVARCHAR(MAX) NULL;
+IF COL_LENGTH('AuditLog', 'IpAddress') IS NULL
+    ALTER TABLE AuditLog ADD IpAddress NVARCHAR(45) NULL;
+IF COL_LENGTH('AuditLog', 'StadiumId') IS NULL
+    ALTER TABLE AuditLog ADD StadiumId INT NULL;
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_AuditLog_StadiumId')
+    CREATE INDEX IX_AuditLog_StadiumId ON AuditLog(StadiumId, CreatedAt);
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_AuditLog_ActorId')
+    CREATE INDEX IX_AuditLog_ActorId ON AuditLog(ActorId, CreatedAt);
+GO
+
+-- The audit log is append-only: reject every update and delete, whoever issues it
+IF OBJECT_ID('TR_AuditLog_Immutable', 'TR') IS NULL
+    EXEC('CREATE TRIGGER TR_AuditLog_Immutable ON AuditLog INSTEAD OF UPDATE, DELETE AS
+    BEGIN
+        RAISERROR(''AuditLog entries cannot be changed or deleted'', 16, 1);
+        ROLLBACK TRANSACTION;
+    END');
+GO
+
+-- Migration: email verification. Accounts created before verification existed are treated as verified.
+IF COL_LENGTH('Users', 'EmailVerified') IS NULL
+BEGIN
+    ALTER TABLE Users ADD EmailVerified BIT NOT NULL DEFAULT 0;
+    EXEC('UPDATE Users SET EmailVerified = 1');
+END
+IF COL_LENGTH('Users', 'EmailVerifiedAt') IS NULL
+    ALTER TABLE Users ADD EmailVerifiedAt DATETIME NULL;
+GO
+
+-- Single-use tokens mailed to users (email verification and similar). Only a SHA-256 hash of the
+-- token is stored, so a database leak doesn't hand out working links.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='UserTokens' AND xtype='U')
+CREATE TABLE UserTokens (
+    TokenId INT PRIMARY KEY IDENTITY(1,1),
+    UserId INT NOT NULL,
+    Purpose NVARCHAR(50) NOT NULL,
+    TokenHash CHAR(64) NOT NULL UNIQUE,
+    ExpiresAt DATETIME NOT NULL,
+    UsedAt DATETIME NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_UserTokens_UserId')
+    CREATE INDEX IX_UserTokens_UserId ON UserTokens(UserId, Purpose);
+GO
+
+-- Development mail outbox, used when MAIL_TRANSPORT=db
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='MailOutbox' AND xtype='U')
+CREATE TABLE MailOutbox (
+    MailId INT PRIMARY KEY IDENTITY(1,1),
+    Recipient NVARCHAR(255) NOT NULL,
+    Subject NVARCHAR(255) NOT NULL,
+    Body NVARCHAR(MAX) NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE()
+);
+GO
+
+-- Migration: session metadata and sliding expiry. ExpiresAt is the idle deadline and moves forward on
+-- use; AbsoluteExpiresAt caps how long a session can live however active it is.
+IF COL_LENGTH('Sessions', 'UserAgent') IS NULL
+    ALTER TABLE Sessions ADD UserAgent NVARCHAR(500) NOT NULL DEFAULT '';
+IF COL_LENGTH('Sessions', 'IpAddress') IS NULL
+    ALTER TABLE Sessions ADD IpAddress NVARCHAR(45) NOT NULL DEFAULT '';
+IF COL_LENGTH('Sessions', 'CreatedAt') IS NULL
+    ALTER TABLE Sessions ADD CreatedAt DATETIME NOT NULL DEFAULT GETDATE();
+IF COL_LENGTH('Sessions', 'LastSeenAt') IS NULL
+    ALTER TABLE Sessions ADD LastSeenAt DATETIME NOT NULL DEFAULT GETDATE();
+IF COL_LENGTH('Sessions', 'RememberMe') IS NULL
+    ALTER TABLE Sessions ADD RememberMe BIT NOT NULL DEFAULT 0;
+IF COL_LENGTH('Sessions', 'AbsoluteExpiresAt') IS NULL
+BEGIN
+    ALTER TABLE Sessions ADD AbsoluteExpiresAt DATETIME NULL;
+    EXEC('UPDATE Sessions SET AbsoluteExpiresAt = ExpiresAt');
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_Sessions_UserId')
+    CREATE INDEX IX_Sessions_UserId ON Sessions(UserId);
+GO
+
+-- Migration: TOTP two-factor authentication. The secret lives in its own table; Users.TwoFactorEnabled
+-- is a denormalized flag so every session lookup doesn't need the join.
+IF COL_LENGTH('Users', 'TwoFactorEnabled') IS NULL
+    ALTER TABLE Users ADD TwoFactorEnabled BIT NOT NULL DEFAULT 0;
+IF COL_LENGTH('Stadiums', 'RequireStaffTwoFactor') IS NULL
+    ALTER TABLE Stadiums ADD RequireStaffTwoFactor BIT NOT NULL DEFAULT 0;
+IF COL_LENGTH('UserTokens', 'Attempts') IS NULL
+    ALTER TABLE UserTokens ADD Attempts INT NOT NULL DEFAULT 0;
+GO
+
+-- LastUsedStep is the TOTP time step of the last accepted code, so a code can't be replayed
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='UserTwoFactor' AND xtype='U')
+CREATE TABLE UserTwoFactor (
+    UserId INT PRIMARY KEY,
+    Secret NVARCHAR(64) NOT NULL,
+    EnabledAt DATETIME NULL,
+    LastUsedStep BIGINT NOT NULL DEFAULT 0,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE
+);
+GO
+
+-- One-time recovery codes, stored as SHA-256 hashes
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='UserRecoveryCodes' AND xtype='U')
+CREATE TABLE UserRecoveryCodes (
+    CodeId INT PRIMARY KEY IDENTITY(1,1),
+    UserId INT NOT NULL,
+    CodeHash CHAR(64) NOT NULL,
+    UsedAt DATETIME NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_UserRecoveryCodes_UserId')
+    CREATE INDEX IX_UserRecoveryCodes_UserId ON UserRecoveryCodes(UserId);
+GO
+
+-- Migration: sign-in through external OpenID Connect providers. An account can have one identity per
+-- provider; the provider's subject is the stable key, the email is only a copy for display.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='UserIdentities' AND xtype='U')
+CREATE TABLE UserIdentities (
+    IdentityId INT PRIMARY KEY IDENTITY(1,1),
+    UserId INT NOT NULL,
+    Provider NVARCHAR(50) NOT NULL,
+    Subject NVARCHAR(255) NOT NULL,
+    Email NVARCHAR(255) NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    LastLoginAt DATETIME NULL,
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE,
+    CONSTRAINT UQ_UserIdentities_ProviderSubject UNIQUE (Provider, Subject),
+    CONSTRAINT UQ_UserIdentities_UserProvider UNIQUE (UserId, Provider)
+);
+GO
+
+-- In-flight OIDC logins. State is looked up by hash; the nonce and PKCE verifier never leave the server.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='OidcLoginStates' AND xtype='U')
+CREATE TABLE OidcLoginStates (
+    StateHash CHAR(64) PRIMARY KEY,
+    Provider NVARCHAR(50) NOT NULL,
+    Nonce NVARCHAR(100) NOT NULL,
+    CodeVerifier NVARCHAR(100) NOT NULL,
+    RememberMe BIT NOT NULL DEFAULT 0,
+    ExpiresAt DATETIME NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE()
+);
+GO
+
+-- Migration: login brute-force protection. LoginThrottles holds the running count of failed logins per
+-- account (by email, whether or not the account exists) and per IP address; AccountLockouts keeps a
+-- record of every lockout for admins.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='LoginThrottles' AND xtype='U')
+CREATE TABLE LoginThrottles (
+    Scope NVARCHAR(10) NOT NULL,
+    ThrottleKey NVARCHAR(255) NOT NULL,
+    Failures INT NOT NULL DEFAULT 0,
+    LastFailureAt DATETIME NOT NULL,
+    BlockedUntil DATETIME NULL,
+    CONSTRAINT PK_LoginThrottles PRIMARY KEY (Scope, ThrottleKey)
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='AccountLockouts' AND xtype='U')
+CREATE TABLE AccountLockouts (
+    LockoutId INT PRIMARY KEY IDENTITY(1,1),
+    Scope NVARCHAR(10) NOT NULL,
+    ThrottleKey NVARCHAR(255) NOT NULL,
+    UserId INT NULL,
+    Failures INT NOT NULL,
+    LockedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    LockedUntil DATETIME NOT NULL,
+    ClearedAt DATETIME NULL,
+    ClearedBy INT NULL,
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE SET NULL,
+    FOREIGN KEY (ClearedBy) REFERENCES Users(UserId)
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_AccountLockouts_LockedAt')
+    CREATE INDEX IX_AccountLockouts_LockedAt ON AccountLockouts(LockedAt DESC);
+GO
+
+-- Migration: API keys for kiosks and partner integrations. Only a SHA-256 hash of the key is kept;
+-- KeyPrefix is the start of the key so owners can tell their keys apart. Scopes is comma-separated.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='ApiKeys' AND xtype='U')
+CREATE TABLE ApiKeys (
+    KeyId INT PRIMARY KEY IDENTITY(1,1),
+    UserId INT NOT NULL,
+    Name NVARCHAR(100) NOT NULL,
+    KeyPrefix NVARCHAR(20) NOT NULL,
+    KeyHash CHAR(64) NOT NULL UNIQUE,
+    Scopes NVARCHAR(200) NOT NULL,
+    ExpiresAt DATETIME NULL,
+    LastUsedAt DATETIME NULL,
+    LastUsedIp NVARCHAR(45) NULL,
+    RevokedAt DATETIME NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT GETDATE(),
+    FOREIGN KEY (UserId) REFERENCES Users(UserId) ON DELETE CASCADE
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_ApiKeys_UserId')
+    CREATE INDEX IX_ApiKeys_UserId ON ApiKeys(UserId);
+GO
+
+-- Migration: account self-service. An email change waits in PendingEmail until the new address is
+-- confirmed. Deleted accounts keep their row, stripped of personal data, so booking history survives;
+-- Bookings no longer cascade from Users, so a hard delete can't silently take that history with it.
+IF COL_LENGTH('Users', 'PendingEmail') IS NULL
+    ALTER TABLE Users ADD PendingEmail NVARCHAR(255) NULL;
+IF COL_LENGTH('Users', 'DeletedAt') IS NULL
+    ALTER TABLE Users ADD DeletedAt DATETIME NULL;
+GO
+
+IF OBJECT_ID('CK_Users_Status', 'C') IS NULL
+BEGIN
+    DECLARE @StatusCheck NVARCHAR(128);
+    SELECT @StatusCheck = cc.name
+    FROM sys.check_constraints cc
+    INNER JOIN sys.columns c ON c.object_id = cc.parent_object_id AND c.column_id = cc.parent_column_id
+    WHERE c.name = 'Status' AND cc.parent_object_id = OBJECT_ID('Users');
+    IF @StatusCheck IS NOT NULL
+        EXEC('ALTER TABLE Users DROP CONSTRAINT ' + @StatusCheck);
+    ALTER TABLE Users ADD CONSTRAINT CK_Users_Status CHECK (Status IN ('Active', 'Suspended', 'Deleted'));
+END
+GO
+
+IF OBJECT_ID('FK_Bookings_UserId', 'F') IS NULL
+BEGIN
+    DECLARE @BookingUserFK NVARCHAR(128);
+    SELECT @BookingUserFK = fk.name
+    FROM sys.foreign_keys fk
+    INNER JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+    INNER JOIN sys.columns c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id
+    WHERE fk.parent_object_id = OBJECT_ID('Bookings') AND fk.referenced_object_id = OBJECT_ID('Users') AND c.name = 'UserId';
+    IF @BookingUserFK IS NOT NULL
+        EXEC('ALTER TABLE Bookings DROP CONSTRAINT ' + @BookingUserFK);
+    ALTER TABLE Bookings ADD CONSTRAINT FK_Bookings_UserId FOREIGN KEY (UserId) REFERENCES Users(UserId);
+END
+GO
+
+-- Migration: schema versioning. The server's readiness check compares the newest version recorded here
+-- with config.SchemaVersion. Version 1 is everything above; each later migration ends by recording its
+-- own number.
+IF NOT EXISTS (SELECT * FROM sysobjects WHERE name='SchemaVersions' AND xtype='U')
+CREATE TABLE SchemaVersions (
+    Version INT PRIMARY KEY,
+    AppliedAt DATETIME NOT NULL DEFAULT GETDATE()
+);
+GO
+
+IF NOT EXISTS (SELECT * FROM SchemaVersions WHERE Version = 1)
+    INSERT INTO SchemaVersions (Version) VALUES (1);
+GO
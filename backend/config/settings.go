@@ -0,0 +1,580 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings is the server's whole configuration. Load builds it once at startup and main hands each
+// part to the package that needs it; nothing reads the environment after that.
+type Settings struct {
+	Server   ServerSettings
+	App      AppSettings
+	CORS     CORSSettings
+	Database DatabaseSettings
+	Session  SessionSettings
+	Slots    SlotSettings
+	Password PasswordSettings
+	Cache    CacheSettings
+	Mail     MailSettings
+	OIDC     OIDCSettings
+}
+
+// ServerSettings holds the HTTP server's limits. ReadTimeout and WriteTimeout bound a whole request
+// and response; IdleTimeout is how long a keep-alive connection waits for the next request. On
+// shutdown, in-flight requests and background jobs get ShutdownTimeout to finish.
+// TrustedProxies are the reverse proxies whose X-Forwarded-* headers are believed.
+type ServerSettings struct {
+	Addr              string
+	TrustedProxies    []*net.IPNet
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	CleanupInterval   time.Duration
+}
+
+type AppSettings struct {
+	// BaseURL is the public origin of the web app, used to build links in emails and OIDC callbacks
+	BaseURL string
+}
+
+type CORSSettings struct {
+	AllowedOrigins []string
+}
+
+type DatabaseSettings struct {
+	ConnectionString string
+}
+
+// SessionSettings sets how long sessions last. A session expires after IdleTimeout without use, and
+// after AbsoluteTimeout regardless of use. "Remember me" sessions use the Remember* pair instead.
+type SessionSettings struct {
+	IdleTimeout             time.Duration
+	AbsoluteTimeout         time.Duration
+	RememberIdleTimeout     time.Duration
+	RememberAbsoluteTimeout time.Duration
+}
+
+// SlotSettings bounds the bookable part of each day, in UTC hours
+type SlotSettings struct {
+	DayStartHour int
+	DayEndHour   int
+}
+
+type PasswordSettings struct {
+	MinLength       int
+	MaxLength       int
+	RequiredClasses []string
+	BreachList      string
+	BreachMinCount  int
+}
+
+type CacheSettings struct {
+	Backend       string
+	TTL           time.Duration
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+type MailSettings struct {
+	Transport    string
+	OutboxDir    string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+type OIDCSettings struct {
+	Providers []OIDCProviderSettings
+}
+
+type OIDCProviderSettings struct {
+	Name         string   `json:"-"`
+	DisplayName  string   `json:"displayName"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	RedirectURL  string   `json:"redirectUrl"`
+	Scopes       []string `json:"scopes"`
+}
+
+// bcrypt ignores everything past 72 bytes
+const bcryptMaxBytes = 72
+
+var (
+	passwordClasses = []string{"lower", "upper", "digit", "symbol"}
+	cacheBackends   = []string{"memory", "redis", "none"}
+	mailTransports  = []string{"log", "file", "db", "smtp"}
+)
+
+// Defaults returns the settings used when nothing else is configured
+func Defaults() *Settings {
+	return &Settings{
+		Server: ServerSettings{
+			Addr:              ":8080",
+			TrustedProxies:    mustParseNetworks("127.0.0.1/32", "::1/128"),
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+			CleanupInterval:   time.Hour,
+		},
+		App: AppSettings{
+			BaseURL: "http://localhost:8080",
+		},
+		Database: DatabaseSettings{
+			ConnectionString: "server=localhost,1433;database=BookMyArena;trusted_connection=yes;encrypt=disable",
+		},
+		Session: SessionSettings{
+			IdleTimeout:             24 * time.Hour,
+			AbsoluteTimeout:         7 * 24 * time.Hour,
+			RememberIdleTimeout:     30 * 24 * time.Hour,
+			RememberAbsoluteTimeout: 90 * 24 * time.Hour,
+		},
+		Slots: SlotSettings{
+			DayStartHour: 8,
+			DayEndHour:   22,
+		},
+		Password: PasswordSettings{
+			MinLength:      8,
+			MaxLength:      bcryptMaxBytes,
+			BreachMinCount: 1,
+		},
+		Cache: CacheSettings{
+			Backend:   "memory",
+			TTL:       time.Minute,
+			RedisAddr: "localhost:6379",
+		},
+		Mail: MailSettings{
+			Transport: "log",
+			OutboxDir: "mail-outbox",
+			From:      "BookMyArena <no-reply@bookmyarena.local>",
+			SMTPPort:  587,
+		},
+	}
+}
+
+// option is one setting. Key names it in the config file (dots separate sections) and on the
+// command line; Env is the environment variable that sets it.
+type option struct {
+	Key   string
+	Env   string
+	Usage string
+	Value flag.Value
+}
+
+func (s *Settings) options() []option {
+	return []option{
+		{"server.addr", "SERVER_ADDR", "address the HTTP server listens on", (*stringValue)(&s.Server.Addr)},
+		{"server.trustedProxies", "TRUSTED_PROXIES", "reverse proxies whose X-Forwarded-For and X-Forwarded-Proto are believed, as addresses or CIDR ranges (comma-separated)", (*networksValue)(&s.Server.TrustedProxies)},
+		{"server.readHeaderTimeout", "SERVER_READ_HEADER_TIMEOUT", "time allowed to read request headers", (*durationValue)(&s.Server.ReadHeaderTimeout)},
+		{"server.readTimeout", "SERVER_READ_TIMEOUT", "time allowed to read a whole request", (*durationValue)(&s.Server.ReadTimeout)},
+		{"server.writeTimeout", "SERVER_WRITE_TIMEOUT", "time allowed to write a response", (*durationValue)(&s.Server.WriteTimeout)},
+		{"server.idleTimeout", "SERVER_IDLE_TIMEOUT", "how long an idle keep-alive connection stays open", (*durationValue)(&s.Server.IdleTimeout)},
+		{"server.shutdownTimeout", "SERVER_SHUTDOWN_TIMEOUT", "how long shutdown waits for in-flight requests and jobs", (*durationValue)(&s.Server.ShutdownTimeout)},
+		{"server.cleanupInterval", "CLEANUP_INTERVAL", "how often expired sessions, login states and throttles are removed", (*durationValue)(&s.Server.CleanupInterval)},
+		{"app.baseURL", "APP_BASE_URL", "public address of the web app, used in email links", (*stringValue)(&s.App.BaseURL)},
+		{"cors.allowedOrigins", "CORS_ALLOWED_ORIGINS", "other origins that may call the API from a browser (comma-separated)", (*listValue)(&s.CORS.AllowedOrigins)},
+		{"database.connectionString", "DB_CONNECTION_STRING", "SQL Server connection string", (*stringValue)(&s.Database.ConnectionString)},
+		{"session.idleTimeout", "SESSION_IDLE_TIMEOUT", "session lifetime without use", (*durationValue)(&s.Session.IdleTimeout)},
+		{"session.absoluteTimeout", "SESSION_ABSOLUTE_TIMEOUT", "session lifetime regardless of use", (*durationValue)(&s.Session.AbsoluteTimeout)},
+		{"session.rememberIdleTimeout", "SESSION_REMEMBER_IDLE_TIMEOUT", "\"remember me\" session lifetime without use", (*durationValue)(&s.Session.RememberIdleTimeout)},
+		{"session.rememberAbsoluteTimeout", "SESSION_REMEMBER_ABSOLUTE_TIMEOUT", "\"remember me\" session lifetime regardless of use", (*durationValue)(&s.Session.RememberAbsoluteTimeout)},
+		{"slots.dayStartHour", "SLOT_DAY_START_HOUR", "first bookable hour of the day (UTC)", (*intValue)(&s.Slots.DayStartHour)},
+		{"slots.dayEndHour", "SLOT_DAY_END_HOUR", "hour the last slot must end by (UTC)", (*intValue)(&s.Slots.DayEndHour)},
+		{"password.minLength", "PASSWORD_MIN_LENGTH", "minimum password length in characters", (*intValue)(&s.Password.MinLength)},
+		{"password.maxLength", "PASSWORD_MAX_LENGTH", "maximum password length in bytes (at most 72)", (*intValue)(&s.Password.MaxLength)},
+		{"password.requiredClasses", "PASSWORD_REQUIRED_CLASSES", "character classes a password must contain: lower, upper, digit, symbol (comma-separated)", (*listValue)(&s.Password.RequiredClasses)},
+		{"password.breachList", "PASSWORD_BREACH_LIST", "file or directory of breached password hashes to reject", (*stringValue)(&s.Password.BreachList)},
+		{"password.breachMinCount", "PASSWORD_BREACH_MIN_COUNT", "ignore breach list entries seen fewer times than this", (*intValue)(&s.Password.BreachMinCount)},
+		{"cache.backend", "CACHE_BACKEND", "session and user cache: memory, redis or none", (*stringValue)(&s.Cache.Backend)},
+		{"cache.ttl", "CACHE_TTL", "how long cache entries live", (*durationValue)(&s.Cache.TTL)},
+		{"cache.redisAddr", "REDIS_ADDR", "Redis address for the redis cache", (*stringValue)(&s.Cache.RedisAddr)},
+		{"cache.redisPassword", "REDIS_PASSWORD", "Redis password", (*stringValue)(&s.Cache.RedisPassword)},
+		{"cache.redisDB", "REDIS_DB", "Redis database number", (*intValue)(&s.Cache.RedisDB)},
+		{"mail.transport", "MAIL_TRANSPORT", "how email is delivered: log, file, db or smtp", (*stringValue)(&s.Mail.Transport)},
+		{"mail.outboxDir", "MAIL_OUTBOX_DIR", "directory for the file transport", (*stringValue)(&s.Mail.OutboxDir)},
+		{"mail.from", "MAIL_FROM", "sender address of outgoing email", (*stringValue)(&s.Mail.From)},
+		{"mail.smtpHost", "SMTP_HOST", "SMTP relay host, required for the smtp transport", (*stringValue)(&s.Mail.SMTPHost)},
+		{"mail.smtpPort", "SMTP_PORT", "SMTP relay port", (*intValue)(&s.Mail.SMTPPort)},
+		{"mail.smtpUsername", "SMTP_USERNAME", "SMTP username", (*stringValue)(&s.Mail.SMTPUsername)},
+		{"mail.smtpPassword", "SMTP_PASSWORD", "SMTP password", (*stringValue)(&s.Mail.SMTPPassword)},
+	}
+}
+
+// Load builds the settings from, in increasing order of precedence: the defaults, a JSON config file
+// (named by -config or CONFIG_FILE), environment variables and command-line flags. -help lists every
+// flag with its environment variable and default.
+func Load(args []string) (*Settings, error) {
+	s := Defaults()
+	options := s.options()
+
+	fs := flag.NewFlagSet("bookmyarena", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "JSON config file (env CONFIG_FILE)")
+	for _, opt := range options {
+		fs.Var(opt.Value, opt.Key, fmt.Sprintf("%s (env %s)", opt.Usage, opt.Env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	// Flags are parsed first only to find the config file; remember them so they still win
+	fromFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { fromFlags[f.Name] = true })
+
+	var fileProviders map[string]OIDCProviderSettings
+	if *configFile != "" {
+		values, providers, err := readConfigFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+		fileProviders = providers
+
+		known := map[string]bool{}
+		for _, opt := range options {
+			known[opt.Key] = true
+		}
+		for key := range values {
+			if !known[key] {
+				return nil, fmt.Errorf("%s: unknown setting %q", *configFile, key)
+			}
+		}
+
+		for _, opt := range options {
+			value, ok := values[opt.Key]
+			if !ok || fromFlags[opt.Key] {
+				continue
+			}
+			if err := opt.Value.Set(value); err != nil {
+				return nil, fmt.Errorf("%s: invalid %s %q: %v", *configFile, opt.Key, value, err)
+			}
+		}
+	}
+
+	for _, opt := range options {
+		value := os.Getenv(opt.Env)
+		if value == "" || fromFlags[opt.Key] {
+			continue
+		}
+		if err := opt.Value.Set(value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", opt.Env, value, err)
+		}
+	}
+
+	s.OIDC.Providers = oidcProviders(fileProviders)
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate reports every problem with the settings at once
+func (s *Settings) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(s.Server.Addr != "", "server.addr is required")
+	check(s.Server.ReadHeaderTimeout > 0 && s.Server.ReadTimeout > 0 && s.Server.WriteTimeout > 0 &&
+		s.Server.IdleTimeout > 0 && s.Server.ShutdownTimeout > 0,
+		"server timeouts must be positive")
+	check(s.Server.ReadHeaderTimeout <= s.Server.ReadTimeout, "server.readHeaderTimeout must not exceed server.readTimeout")
+	check(s.Server.CleanupInterval > 0, "server.cleanupInterval must be positive")
+
+	base, err := url.Parse(s.App.BaseURL)
+	check(err == nil && (base.Scheme == "http" || base.Scheme == "https") && base.Host != "",
+		"app.baseURL must be an absolute http(s) URL, got %q", s.App.BaseURL)
+	for _, origin := range s.CORS.AllowedOrigins {
+		u, err := url.Parse(origin)
+		check(err == nil && u.Scheme != "" && u.Host != "" && (u.Path == "" || u.Path == "/"),
+			"cors.allowedOrigins: %q is not an origin such as https://app.example.com", origin)
+	}
+
+	check(s.Database.ConnectionString != "", "database.connectionString is required")
+
+	check(s.Session.IdleTimeout > 0 && s.Session.AbsoluteTimeout > 0 &&
+		s.Session.RememberIdleTimeout > 0 && s.Session.RememberAbsoluteTimeout > 0,
+		"session timeouts must be positive")
+	check(s.Session.IdleTimeout <= s.Session.AbsoluteTimeout, "session.idleTimeout must not exceed session.absoluteTimeout")
+	check(s.Session.RememberIdleTimeout <= s.Session.RememberAbsoluteTimeout,
+		"session.rememberIdleTimeout must not exceed session.rememberAbsoluteTimeout")
+
+	check(s.Slots.DayStartHour >= 0 && s.Slots.DayEndHour <= 24 && s.Slots.DayStartHour < s.Slots.DayEndHour,
+		"slots.dayStartHour and slots.dayEndHour must satisfy 0 <= start < end <= 24")
+
+	check(s.Password.MinLength > 0, "password.minLength must be positive")
+	check(s.Password.MaxLength > 0 && s.Password.MaxLength <= bcryptMaxBytes,
+		"password.maxLength must be between 1 and %d (bcrypt ignores longer passwords)", bcryptMaxBytes)
+	check(s.Password.MinLength <= s.Password.MaxLength, "password.minLength must not exceed password.maxLength")
+	for _, class := range s.Password.RequiredClasses {
+		check(contains(passwordClasses, class), "password.requiredClasses: unknown class %q, expected one of %s",
+			class, strings.Join(passwordClasses, ", "))
+	}
+	check(s.Password.BreachMinCount > 0, "password.breachMinCount must be positive")
+
+	check(contains(cacheBackends, s.Cache.Backend), "cache.backend must be one of %s", strings.Join(cacheBackends, ", "))
+	check(s.Cache.TTL >= 0, "cache.ttl must not be negative")
+	check(s.Cache.RedisDB >= 0, "cache.redisDB must not be negative")
+	check(s.Cache.Backend != "redis" || s.Cache.RedisAddr != "", "cache.redisAddr is required for the redis cache")
+
+	check(contains(mailTransports, s.Mail.Transport), "mail.transport must be one of %s", strings.Join(mailTransports, ", "))
+	check(s.Mail.Transport != "smtp" || s.Mail.SMTPHost != "", "mail.smtpHost is required for the smtp transport")
+	check(s.Mail.Transport != "file" || s.Mail.OutboxDir != "", "mail.outboxDir is required for the file transport")
+	check(s.Mail.SMTPPort > 0 && s.Mail.SMTPPort < 65536, "mail.smtpPort must be a port number")
+
+	for _, p := range s.OIDC.Providers {
+		check(p.Issuer != "", "oidc provider %q: issuer is required", p.Name)
+		check(p.ClientID != "", "oidc provider %q: client ID is required", p.Name)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// readConfigFile flattens the file's nested sections into dotted keys, e.g.
+// {"session": {"idleTimeout": "12h"}} becomes session.idleTimeout=12h. The "oidc" section is a map of
+// provider name to provider settings and is returned separately.
+func readConfigFile(path string) (map[string]string, map[string]OIDCProviderSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	var providers map[string]OIDCProviderSettings
+	if raw, ok := root["oidc"]; ok {
+		var section struct {
+			Providers map[string]OIDCProviderSettings `json:"providers"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&section); err != nil {
+			return nil, nil, fmt.Errorf("%s: oidc: %v", path, err)
+		}
+		providers = section.Providers
+		delete(root, "oidc")
+	}
+
+	values := map[string]string{}
+	for section, raw := range root {
+		var fields map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s must be an object: %v", path, section, err)
+		}
+		for name, value := range fields {
+			text, err := settingText(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s.%s: %v", path, section, name, err)
+			}
+			values[section+"."+name] = text
+		}
+	}
+
+	return values, providers, nil
+}
+
+// settingText turns a JSON value into the same text an environment variable would hold
+func settingText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				return "", errors.New("list items must be strings")
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", errors.New("expected a string, number, boolean or list of strings")
+}
+
+// oidcProviders combines providers from the config file with the environment. OIDC_PROVIDERS, if set,
+// names the providers to use; each reads OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _DISPLAY_NAME,
+// _SCOPES and _REDIRECT_URL, which override the file's values.
+func oidcProviders(fromFile map[string]OIDCProviderSettings) []OIDCProviderSettings {
+	var names []string
+	if list := os.Getenv("OIDC_PROVIDERS"); list != "" {
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+	} else {
+		for name := range fromFile {
+			names = append(names, strings.ToLower(name))
+		}
+		sort.Strings(names)
+	}
+
+	fileByName := map[string]OIDCProviderSettings{}
+	for name, p := range fromFile {
+		fileByName[strings.ToLower(name)] = p
+	}
+
+	var providers []OIDCProviderSettings
+	for _, name := range names {
+		p := fileByName[name]
+		p.Name = name
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		envString(prefix+"DISPLAY_NAME", &p.DisplayName)
+		envString(prefix+"ISSUER", &p.Issuer)
+		envString(prefix+"CLIENT_ID", &p.ClientID)
+		envString(prefix+"CLIENT_SECRET", &p.ClientSecret)
+		envString(prefix+"REDIRECT_URL", &p.RedirectURL)
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			p.Scopes = strings.Fields(scopes)
+		}
+
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+func envString(name string, target *string) {
+	if value := os.Getenv(name); value != "" {
+		*target = value
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// flag.Value implementations that write straight into the Settings fields
+
+type stringValue string
+
+func (v *stringValue) String() string     { return string(*v) }
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+
+type intValue int
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return errors.New("expected a whole number")
+	}
+	*v = intValue(n)
+	return nil
+}
+
+type durationValue time.Duration
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return errors.New("expected a duration such as 30s or 12h")
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+// networksValue is a comma-separated list of CIDR ranges; a bare address is a range of one
+type networksValue []*net.IPNet
+
+func (v *networksValue) String() string {
+	items := make([]string, len(*v))
+	for i, network := range *v {
+		items[i] = network.String()
+	}
+	return strings.Join(items, ",")
+}
+
+func (v *networksValue) Set(s string) error {
+	var networks []*net.IPNet
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return fmt.Errorf("%q is not an address or CIDR range", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return fmt.Errorf("%q is not an address or CIDR range", item)
+		}
+		networks = append(networks, network)
+	}
+	*v = networks
+	return nil
+}
+
+func mustParseNetworks(items ...string) []*net.IPNet {
+	var v networksValue
+	if err := v.Set(strings.Join(items, ",")); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// listValue is comma-separated; items are trimmed and empty ones dropped
+type listValue []string
+
+func (v *listValue) String() string { return strings.Join(*v, ",") }
+func (v *listValue) Set(s string) error {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*v = items
+	return nil
+}
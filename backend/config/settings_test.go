@@ -0,0 +1,263 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv hides any settings the machine running the tests has in its environment
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("OIDC_PROVIDERS", "")
+	for _, opt := range Defaults().options() {
+		t.Setenv(opt.Env, "")
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := `{"server": {"addr": ":1001"}}`
+
+	tests := []struct {
+		name     string
+		file     string
+		fileEnv  bool // name the file with CONFIG_FILE instead of -config
+		env      string
+		flag     string
+		wantAddr string
+	}{
+		{name: "defaults", wantAddr: ":8080"},
+		{name: "file over defaults", file: file, wantAddr: ":1001"},
+		{name: "file named by CONFIG_FILE", file: file, fileEnv: true, wantAddr: ":1001"},
+		{name: "env over file", file: file, env: ":1002", wantAddr: ":1002"},
+		{name: "env over defaults", env: ":1002", wantAddr: ":1002"},
+		{name: "flag over env and file", file: file, env: ":1002", flag: ":1003", wantAddr: ":1003"},
+		{name: "flag over file", file: file, flag: ":1003", wantAddr: ":1003"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			var args []string
+			if tt.file != "" {
+				path := writeConfigFile(t, tt.file)
+				if tt.fileEnv {
+					t.Setenv("CONFIG_FILE", path)
+				} else {
+					args = append(args, "-config", path)
+				}
+			}
+			if tt.env != "" {
+				t.Setenv("SERVER_ADDR", tt.env)
+			}
+			if tt.flag != "" {
+				args = append(args, "-server.addr", tt.flag)
+			}
+
+			settings, err := Load(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if settings.Server.Addr != tt.wantAddr {
+				t.Errorf("server.addr = %q, want %q", settings.Server.Addr, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestLoadValueTypes(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `{
+		"session": {"idleTimeout": "12h"},
+		"slots": {"dayStartHour": 6, "dayEndHour": "23"},
+		"password": {"requiredClasses": ["lower", "digit"]},
+		"cors": {"allowedOrigins": "https://a.example.com, ,https://b.example.com"}
+	}`)
+	t.Setenv("SESSION_ABSOLUTE_TIMEOUT", "48h")
+	t.Setenv("PASSWORD_MIN_LENGTH", " 10 ")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.4,::1")
+
+	settings, err := Load([]string{"-config", path, "-mail.smtpPort", "2525"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if settings.Session.IdleTimeout != 12*time.Hour || settings.Session.AbsoluteTimeout != 48*time.Hour {
+		t.Errorf("session timeouts = %s, %s, want 12h, 48h", settings.Session.IdleTimeout, settings.Session.AbsoluteTimeout)
+	}
+	if settings.Slots.DayStartHour != 6 || settings.Slots.DayEndHour != 23 {
+		t.Errorf("slot hours = %d-%d, want 6-23", settings.Slots.DayStartHour, settings.Slots.DayEndHour)
+	}
+	if want := []string{"lower", "digit"}; !reflect.DeepEqual(settings.Password.RequiredClasses, want) {
+		t.Errorf("password.requiredClasses = %q, want %q", settings.Password.RequiredClasses, want)
+	}
+	if want := []string{"https://a.example.com", "https://b.example.com"}; !reflect.DeepEqual(settings.CORS.AllowedOrigins, want) {
+		t.Errorf("cors.allowedOrigins = %q, want %q", settings.CORS.AllowedOrigins, want)
+	}
+	if settings.Password.MinLength != 10 {
+		t.Errorf("password.minLength = %d, want 10", settings.Password.MinLength)
+	}
+	if settings.Mail.SMTPPort != 2525 {
+		t.Errorf("mail.smtpPort = %d, want 2525", settings.Mail.SMTPPort)
+	}
+	var proxies []string
+	for _, network := range settings.Server.TrustedProxies {
+		proxies = append(proxies, network.String())
+	}
+	if want := []string{"10.0.0.0/8", "192.0.2.4/32", "::1/128"}; !reflect.DeepEqual(proxies, want) {
+		t.Errorf("server.trustedProxies = %q, want %q", proxies, want)
+	}
+	// Untouched settings keep their defaults
+	if settings.Cache.Backend != "memory" {
+		t.Errorf("cache.backend = %q, want the default", settings.Cache.Backend)
+	}
+}
+
+func TestLoadOIDCProviders(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `{"oidc": {"providers": {
+		"Google": {"displayName": "Google", "issuer": "https://accounts.google.com", "clientId": "file-id", "scopes": ["openid", "email"]},
+		"azure": {"issuer": "https://login.example.com", "clientId": "azure-id"}
+	}}}`)
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "env-id")
+
+	settings, err := Load([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []OIDCProviderSettings{
+		{Name: "azure", Issuer: "https://login.example.com", ClientID: "azure-id"},
+		{Name: "google", DisplayName: "Google", Issuer: "https://accounts.google.com", ClientID: "env-id", Scopes: []string{"openid", "email"}},
+	}
+	if !reflect.DeepEqual(settings.OIDC.Providers, want) {
+		t.Errorf("providers = %+v, want %+v", settings.OIDC.Providers, want)
+	}
+
+	// OIDC_PROVIDERS picks which providers are used
+	t.Setenv("OIDC_PROVIDERS", "google")
+	settings, err = Load([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings.OIDC.Providers) != 1 || settings.OIDC.Providers[0].Name != "google" {
+		t.Errorf("with OIDC_PROVIDERS=google, providers = %+v", settings.OIDC.Providers)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{name: "unknown setting in file", file: `{"server": {"port": 80}}`, wantErr: `unknown setting "server.port"`},
+		{name: "invalid value in file", file: `{"session": {"idleTimeout": "soon"}}`, wantErr: `invalid session.idleTimeout "soon"`},
+		{name: "section not an object", file: `{"server": ":80"}`, wantErr: "server must be an object"},
+		{name: "nested object value", file: `{"server": {"addr": {"port": 80}}}`, wantErr: "server.addr: expected a string"},
+		{name: "unknown oidc field", file: `{"oidc": {"providers": {"x": {"tenant": "y"}}}}`, wantErr: "oidc:"},
+		{name: "malformed file", file: `{"server":`, wantErr: "config.json"},
+		{name: "invalid env value", env: map[string]string{"SLOT_DAY_START_HOUR": "eight"}, wantErr: `invalid SLOT_DAY_START_HOUR "eight"`},
+		{name: "invalid proxy range", env: map[string]string{"TRUSTED_PROXIES": "10.0.0.0/33"}, wantErr: `"10.0.0.0/33" is not an address or CIDR range`},
+		{name: "invalid flag value", args: []string{"-cache.ttl", "1 minute"}, wantErr: "expected a duration"},
+		{name: "unknown flag", args: []string{"-port", "80"}, wantErr: "flag provided but not defined"},
+		{name: "extra argument", args: []string{"serve"}, wantErr: `unexpected argument "serve"`},
+		{name: "missing file", args: []string{"-config", "/nonexistent/config.json"}, wantErr: "open /nonexistent/config.json"},
+		{name: "validated after loading", args: []string{"-cache.backend", "memcached"}, wantErr: "cache.backend must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeConfigFile(t, tt.file)}, args...)
+			}
+
+			_, err := Load(args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(s *Settings)
+		wantErr string
+	}{
+		{"missing address", func(s *Settings) { s.Server.Addr = "" }, "server.addr is required"},
+		{"zero timeout", func(s *Settings) { s.Server.WriteTimeout = 0 }, "server timeouts must be positive"},
+		{"header timeout too long", func(s *Settings) { s.Server.ReadHeaderTimeout = time.Hour }, "server.readHeaderTimeout must not exceed"},
+		{"cleanup interval", func(s *Settings) { s.Server.CleanupInterval = 0 }, "server.cleanupInterval must be positive"},
+		{"relative base URL", func(s *Settings) { s.App.BaseURL = "/app" }, "app.baseURL must be an absolute http(s) URL"},
+		{"origin with a path", func(s *Settings) { s.CORS.AllowedOrigins = []string{"https://a.example.com/app"} }, "is not an origin"},
+		{"missing connection string", func(s *Settings) { s.Database.ConnectionString = "" }, "database.connectionString is required"},
+		{"idle past absolute", func(s *Settings) { s.Session.IdleTimeout = 8 * 24 * time.Hour }, "session.idleTimeout must not exceed"},
+		{"remember idle past absolute", func(s *Settings) { s.Session.RememberIdleTimeout = 100 * 24 * time.Hour }, "session.rememberIdleTimeout must not exceed"},
+		{"slot hours reversed", func(s *Settings) { s.Slots.DayStartHour, s.Slots.DayEndHour = 20, 8 }, "0 <= start < end <= 24"},
+		{"slot hours past midnight", func(s *Settings) { s.Slots.DayEndHour = 25 }, "0 <= start < end <= 24"},
+		{"password too long for bcrypt", func(s *Settings) { s.Password.MaxLength = 100 }, "password.maxLength must be between 1 and 72"},
+		{"min over max", func(s *Settings) { s.Password.MinLength = 50; s.Password.MaxLength = 40 }, "password.minLength must not exceed"},
+		{"unknown class", func(s *Settings) { s.Password.RequiredClasses = []string{"emoji"} }, `unknown class "emoji"`},
+		{"breach count", func(s *Settings) { s.Password.BreachMinCount = 0 }, "password.breachMinCount must be positive"},
+		{"redis without address", func(s *Settings) { s.Cache.Backend = "redis"; s.Cache.RedisAddr = "" }, "cache.redisAddr is required"},
+		{"negative cache TTL", func(s *Settings) { s.Cache.TTL = -time.Second }, "cache.ttl must not be negative"},
+		{"smtp without host", func(s *Settings) { s.Mail.Transport = "smtp" }, "mail.smtpHost is required"},
+		{"file mail without outbox", func(s *Settings) { s.Mail.Transport = "file"; s.Mail.OutboxDir = "" }, "mail.outboxDir is required"},
+		{"bad port", func(s *Settings) { s.Mail.SMTPPort = 70000 }, "mail.smtpPort must be a port number"},
+		{"provider without issuer", func(s *Settings) {
+			s.OIDC.Providers = []OIDCProviderSettings{{Name: "google", ClientID: "id"}}
+		}, `oidc provider "google": issuer is required`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Defaults()
+			tt.change(s)
+			err := s.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	if err := Defaults().Validate(); err != nil {
+		t.Errorf("the defaults are invalid: %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	s := Defaults()
+	s.Server.Addr = ""
+	s.Password.MinLength = 0
+	s.Cache.Backend = "disk"
+
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want an error")
+	}
+	for _, want := range []string{"server.addr is required", "password.minLength must be positive", "cache.backend must be one of"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, missing %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"BookMyArena/backend/middleware"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// UpdateProfile changes the signed-in user's name and starts an email change if the email differs
+func (h *Handlers) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before := map[string]string{"fullName": user.FullName}
+	result, err := services.UpdateProfile(h.deps, user.UserID, req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Addresses stay out of the audit log so deleting the account leaves no trace of them
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserProfileUpdate,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+		Before:     before,
+		After:      map[string]string{"fullName": result.User.FullName},
+		Details:    map[string]bool{"emailChangeRequested": result.PendingEmail != ""},
+	})
+
+	result.User.PasswordHash = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ConfirmEmailChange is opened from the link mailed to the new address, signed in or not
+func (h *Handlers) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, _, err := services.ConfirmEmailChange(req.Token)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		ActorID:    user.UserID,
+		Action:     services.AuditActionUserEmailChanged,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "email address changed"})
+}
+
+// DeleteAccount anonymizes the signed-in user's account and signs them out
+func (h *Handlers) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	cancelled, err := services.DeleteAccount(h.deps, user.UserID, req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserDeleted,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+		Details:    map[string]int{"cancelledBookings": cancelled},
+	})
+
+	clearSessionCookie(w, r)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "account deleted"})
+}
+
+// ExportAccountData returns the user's personal data as a downloadable JSON file
+func (h *Handlers) ExportAccountData(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	export, err := services.ExportAccountData(user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserDataExport,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+	})
+
+	filename := fmt.Sprintf("bookmyarena-export-%d-%s.json", user.UserID, export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(export)
+}
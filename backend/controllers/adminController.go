@@ -0,0 +1,643 @@
+package controllers
+
+import (
+	"BookMyArena/backend/middleware"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Admin routes are gated by permission in routes.SetupRoutes; handlers only need the acting user's ID.
+
+func (h *Handlers) AdminSearchUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	params := models.UserSearchParams{
+		Query:      query.Get("q"),
+		Role:       query.Get("role"),
+		Status:     query.Get("status"),
+		PageNumber: queryInt(r, "pageNumber"),
+		PageSize:   queryInt(r, "pageSize"),
+	}
+
+	result, err := services.SearchUsers(params)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handlers) AdminGetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	user, err := services.GetUserByID(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *Handlers) AdminSuspendUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	var req models.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before, err := services.GetUserByID(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.SuspendUser(admin.UserID, userID, req.Reason); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetUserByID(userID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserSuspended,
+		EntityType: services.AuditEntityUser,
+		EntityID:   userID,
+		Before:     before,
+		After:      after,
+		Details:    map[string]string{"reason": req.Reason},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "account suspended"})
+}
+
+func (h *Handlers) AdminReactivateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	before, err := services.GetUserByID(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.ReactivateUser(admin.UserID, userID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetUserByID(userID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserReactivated,
+		EntityType: services.AuditEntityUser,
+		EntityID:   userID,
+		Before:     before,
+		After:      after,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "account reactivated"})
+}
+
+func (h *Handlers) AdminSetUserRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before, err := services.GetUserByID(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.SetUserRole(admin.UserID, userID, req.Role); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetUserByID(userID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserRoleChanged,
+		EntityType: services.AuditEntityUser,
+		EntityID:   userID,
+		Before:     before,
+		After:      after,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "role updated"})
+}
+
+// AdminGetLockouts lists login lockouts; ?active=true limits it to those still in force
+func (h *Handlers) AdminGetLockouts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	lockouts, err := services.GetAccountLockouts(r.URL.Query().Get("active") == "true", queryInt(r, "limit"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if lockouts == nil {
+		lockouts = []models.AccountLockout{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lockouts)
+}
+
+func (h *Handlers) AdminClearLockout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	lockoutID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid lockout ID")
+		return
+	}
+
+	lockout, err := services.ClearAccountLockout(admin.UserID, lockoutID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionLockoutCleared,
+		EntityType: services.AuditEntityLockout,
+		EntityID:   lockoutID,
+		After:      lockout,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lockout)
+}
+
+func (h *Handlers) AdminPublishStadium(w http.ResponseWriter, r *http.Request) {
+	setStadiumPublished(w, r, true)
+}
+
+func (h *Handlers) AdminUnpublishStadium(w http.ResponseWriter, r *http.Request) {
+	setStadiumPublished(w, r, false)
+}
+
+func setStadiumPublished(w http.ResponseWriter, r *http.Request, published bool) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stadiumID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	var req models.ModerationRequest
+	if !published {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.SetStadiumPublished(admin.UserID, stadiumID, published, req.Reason); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "stadium unpublished"
+	action := services.AuditActionStadiumUnpublished
+	if published {
+		message = "stadium published"
+		action = services.AuditActionStadiumPublished
+	}
+
+	after, _ := services.GetStadiumByID(stadiumID)
+	recordAudit(r, models.AuditEvent{
+		Action:     action,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      after,
+		Details:    map[string]string{"reason": req.Reason},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func (h *Handlers) AdminPublishArena(w http.ResponseWriter, r *http.Request) {
+	setArenaPublished(w, r, true)
+}
+
+func (h *Handlers) AdminUnpublishArena(w http.ResponseWriter, r *http.Request) {
+	setArenaPublished(w, r, false)
+}
+
+func setArenaPublished(w http.ResponseWriter, r *http.Request, published bool) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	arenaID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid arena ID")
+		return
+	}
+
+	var req models.ModerationRequest
+	if !published {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	before, err := services.GetArenaByID(arenaID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.SetArenaPublished(admin.UserID, arenaID, published, req.Reason); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "arena unpublished"
+	action := services.AuditActionArenaUnpublished
+	if published {
+		message = "arena published"
+		action = services.AuditActionArenaPublished
+	}
+
+	after, _ := services.GetArenaByID(arenaID)
+	recordAudit(r, models.AuditEvent{
+		Action:     action,
+		EntityType: services.AuditEntityArena,
+		EntityID:   arenaID,
+		StadiumID:  before.StadiumID,
+		Before:     before,
+		After:      after,
+		Details:    map[string]string{"reason": req.Reason},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func (h *Handlers) AdminGetStadiumReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	reviews, err := services.GetStadiumReviewQueue(r.URL.Query().Get("status"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if reviews == nil {
+		reviews = []models.StadiumReview{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+func (h *Handlers) AdminApproveStadium(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stadiumID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.ApproveStadium(h.deps, admin.UserID, stadiumID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetStadiumByID(stadiumID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumApproved,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      after,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "stadium approved"})
+}
+
+func (h *Handlers) AdminRejectStadium(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stadiumID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	var req models.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.RejectStadium(admin.UserID, stadiumID, req.Reason); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetStadiumByID(stadiumID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumRejected,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      after,
+		Details:    map[string]string{"reason": req.Reason},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "stadium rejected"})
+}
+
+func (h *Handlers) AdminSearchBookings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	params := models.BookingSearchParams{
+		UserID:    queryInt(r, "userId"),
+		StadiumID: queryInt(r, "stadiumId"),
+		ArenaID:   queryInt(r, "arenaId"),
+		Status:    r.URL.Query().Get("status"),
+		Limit:     queryInt(r, "limit"),
+	}
+
+	bookings, err := services.SearchBookings(params)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if bookings == nil {
+		bookings = []models.BookingWithDetails{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookings)
+}
+
+func (h *Handlers) AdminGetBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || bookingID <= 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+
+	bookings, err := services.SearchBookings(models.BookingSearchParams{BookingID: bookingID, Limit: 1})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(bookings) == 0 {
+		utils.RespondWithError(w, http.StatusNotFound, "booking not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookings[0])
+}
+
+func (h *Handlers) AdminCancelBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r)
+	if admin == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	bookingID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+
+	var req models.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before, err := services.GetBookingByID(bookingID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := services.ForceCancelBooking(h.deps, admin.UserID, bookingID, req.Reason); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetBookingByID(bookingID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionBookingForceCancel,
+		EntityType: services.AuditEntityBooking,
+		EntityID:   bookingID,
+		StadiumID:  bookingStadiumID(before),
+		Before:     before,
+		After:      after,
+		Details:    map[string]string{"reason": req.Reason},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "booking cancelled"})
+}
+
+func (h *Handlers) AdminGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	params, err := auditLogParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := services.GetAuditLog(params)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// queryInt reads an integer query parameter, treating a missing or malformed value as 0
+func queryInt(r *http.Request, name string) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"BookMyArena/backend/authz"
 	"BookMyArena/backend/middleware"
 	"BookMyArena/backend/models"
 	"BookMyArena/backend/services"
@@ -13,15 +14,15 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func CreateArena(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) CreateArena(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r)
-	if user == nil || user.Role != "Owner" {
-		utils.RespondWithError(w, http.StatusForbidden, "owner access required")
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -31,31 +32,37 @@ func CreateArena(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" || req.SportType == "" || req.Capacity <= 0 || req.SlotDuration <= 0 || req.Price < 0 {
+	if req.Name == "" || (req.SportType == "" && req.SportTypeID == 0) || req.Capacity <= 0 || req.SlotDuration <= 0 || req.Price < 0 {
 		utils.RespondWithError(w, http.StatusBadRequest, "invalid arena data")
 		return
 	}
 
-	// Verify stadium ownership - VerifyStadiumOwner is in stadiumService but accessible via services package
-	// Since all service files are in the same package, we need to check if VerifyStadiumOwner exists
-	// Let's use the same approach as CreateArena uses
-	if !services.VerifyStadiumOwner(req.StadiumID, user.UserID) {
-		utils.RespondWithError(w, http.StatusForbidden, "you don't own this stadium")
+	// Verify the user owns the stadium or manages it as staff
+	if !services.AuthorizeStadium(user.UserID, req.StadiumID, authz.ArenaManage) {
+		utils.RespondWithError(w, http.StatusForbidden, "you can't manage arenas for this stadium")
 		return
 	}
 
-	arena, err := services.CreateArena(req.StadiumID, req)
+	arena, err := services.CreateArena(h.deps, req.StadiumID, req)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionArenaCreated,
+		EntityType: services.AuditEntityArena,
+		EntityID:   arena.ArenaID,
+		StadiumID:  arena.StadiumID,
+		After:      arena,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(arena)
 }
 
-func GetArena(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetArena(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -81,7 +88,7 @@ func GetArena(w http.ResponseWriter, r *http.Request) {
 		if err == nil {
 			// Get bookings for this date
 			bookings, _ := services.GetBookingsByArena(arenaID)
-			slotAvailability := generateSlotAvailability(arena, date, bookings)
+			slotAvailability := services.GenerateSlotAvailability(h.deps, arena, date, bookings)
 			response := map[string]interface{}{
 				"arena":            arena,
 				"slotAvailability": slotAvailability,
@@ -96,15 +103,15 @@ func GetArena(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(arena)
 }
 
-func UpdateArena(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) UpdateArena(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r)
-	if user == nil || user.Role != "Owner" {
-		utils.RespondWithError(w, http.StatusForbidden, "owner access required")
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -121,7 +128,7 @@ func UpdateArena(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" || req.SportType == "" || req.Capacity <= 0 || req.SlotDuration <= 0 || req.Price < 0 {
+	if req.Name == "" || (req.SportType == "" && req.SportTypeID == 0) || req.Capacity <= 0 || req.SlotDuration <= 0 || req.Price < 0 {
 		utils.RespondWithError(w, http.StatusBadRequest, "invalid arena data")
 		return
 	}
@@ -133,9 +140,9 @@ func UpdateArena(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify stadium ownership
-	if !services.VerifyStadiumOwner(arena.StadiumID, user.UserID) {
-		utils.RespondWithError(w, http.StatusForbidden, "you don't own this arena")
+	// Verify the user owns the stadium or manages it as staff
+	if !services.AuthorizeStadium(user.UserID, arena.StadiumID, authz.ArenaManage) {
+		utils.RespondWithError(w, http.StatusForbidden, "you can't manage this arena")
 		return
 	}
 
@@ -145,19 +152,28 @@ func UpdateArena(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionArenaUpdated,
+		EntityType: services.AuditEntityArena,
+		EntityID:   arenaID,
+		StadiumID:  arena.StadiumID,
+		Before:     arena,
+		After:      updatedArena,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updatedArena)
 }
 
-func DeleteArena(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) DeleteArena(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r)
-	if user == nil || user.Role != "Owner" {
-		utils.RespondWithError(w, http.StatusForbidden, "owner access required")
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -175,9 +191,9 @@ func DeleteArena(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify stadium ownership
-	if !services.VerifyStadiumOwner(arena.StadiumID, user.UserID) {
-		utils.RespondWithError(w, http.StatusForbidden, "you don't own this arena")
+	// Verify the user owns the stadium or manages it as staff
+	if !services.AuthorizeStadium(user.UserID, arena.StadiumID, authz.ArenaManage) {
+		utils.RespondWithError(w, http.StatusForbidden, "you can't manage this arena")
 		return
 	}
 
@@ -187,11 +203,19 @@ func DeleteArena(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionArenaDeleted,
+		EntityType: services.AuditEntityArena,
+		EntityID:   arenaID,
+		StadiumID:  arena.StadiumID,
+		Before:     arena,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "arena deleted successfully"})
 }
 
-func GetArenasByStadium(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetArenasByStadium(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -242,7 +266,7 @@ func GetArenasByStadium(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
-func SearchArenas(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) SearchArenas(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -271,7 +295,7 @@ func SearchArenas(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(arenas)
 }
 
-func GetAllArenas(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetAllArenas(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -291,42 +315,110 @@ func GetAllArenas(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(arenas)
 }
 
-func generateSlotAvailability(arena *models.Arena, date time.Time, bookings []models.Booking) []models.SlotAvailability {
-	// Generate slots for the day (e.g., 8 AM to 10 PM, based on slot duration)
-	startHour := 8
-	endHour := 22
-	slotDuration := time.Duration(arena.SlotDuration) * time.Minute
+func (h *Handlers) FindAvailableSlots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	params := models.SlotSearchParams{
+		SportType: query.Get("sportType"),
+		Location:  query.Get("location"),
+	}
+
+	if v := query.Get("stadiumId"); v != "" {
+		stadiumID, err := strconv.Atoi(v)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+			return
+		}
+		params.StadiumID = stadiumID
+	}
 
-	var slots []models.SlotAvailability
+	if v := query.Get("duration"); v != "" {
+		duration, err := strconv.Atoi(v)
+		if err != nil || duration <= 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid duration")
+			return
+		}
+		params.Duration = duration
+	}
 
-	dayStart := time.Date(date.Year(), date.Month(), date.Day(), startHour, 0, 0, 0, time.UTC)
-	dayEnd := time.Date(date.Year(), date.Month(), date.Day(), endHour, 0, 0, 0, time.UTC)
+	// Limits above the maximum are capped by the service
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		params.Limit = limit
+	}
 
-	currentSlot := dayStart
-	for currentSlot.Add(slotDuration).Before(dayEnd) || currentSlot.Add(slotDuration).Equal(dayEnd) {
-		slotEnd := currentSlot.Add(slotDuration)
+	if v := query.Get("from"); v != "" {
+		from, err := parseSlotSearchTime(v, false)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid from time")
+			return
+		}
+		params.From = from
+	}
 
-		// Check if this slot conflicts with any booking
-		available := true
-		for _, booking := range bookings {
-			if booking.Status == "Cancelled" {
-				continue
-			}
-			// Check if slots overlap
-			if !(slotEnd.Before(booking.SlotStart) || currentSlot.After(booking.SlotEnd)) {
-				available = false
-				break
-			}
+	if v := query.Get("to"); v != "" {
+		to, err := parseSlotSearchTime(v, true)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid to time")
+			return
 		}
+		params.To = to
+	}
 
-		slots = append(slots, models.SlotAvailability{
-			SlotStart: currentSlot,
-			SlotEnd:   slotEnd,
-			Available: available,
-		})
+	slots, err := services.FindNextAvailableSlots(h.deps, params)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if slots == nil {
+		slots = []models.AvailableSlot{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(slots)
+}
+
+// parseSlotSearchTime accepts RFC3339 or a plain date; a plain "to" date covers the whole day
+func parseSlotSearchTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date, nil
+}
 
-		currentSlot = slotEnd
+func (h *Handlers) GetSportTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
 	}
 
-	return slots
+	sportTypes, err := services.GetSportTypes()
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if sportTypes == nil {
+		sportTypes = []models.SportType{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sportTypes)
 }
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"BookMyArena/backend/middleware"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAuditLog lists audit entries for every stadium the signed-in owner owns
+func (h *Handlers) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	params, err := auditLogParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.OwnerID = user.UserID
+
+	respondWithAuditLog(w, params)
+}
+
+func (h *Handlers) GetStadiumAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	stadiumID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	params, err := auditLogParams(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.StadiumID = stadiumID
+
+	respondWithAuditLog(w, params)
+}
+
+func respondWithAuditLog(w http.ResponseWriter, params models.AuditLogParams) {
+	entries, err := services.GetAuditLog(params)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// auditLogParams reads the audit log filters shared by the owner and admin endpoints
+func auditLogParams(r *http.Request) (models.AuditLogParams, error) {
+	query := r.URL.Query()
+	params := models.AuditLogParams{
+		ActorID:    queryInt(r, "actorId"),
+		EntityType: query.Get("entityType"),
+		EntityID:   queryInt(r, "entityId"),
+		StadiumID:  queryInt(r, "stadiumId"),
+		Action:     query.Get("action"),
+		Limit:      queryInt(r, "limit"),
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseSlotSearchTime(from, false)
+		if err != nil {
+			return params, errors.New("invalid from, use YYYY-MM-DD or RFC3339")
+		}
+		params.From = t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := parseSlotSearchTime(to, true)
+		if err != nil {
+			return params, errors.New("invalid to, use YYYY-MM-DD or RFC3339")
+		}
+		params.To = t
+	}
+
+	return params, nil
+}
+
+// recordAudit logs a write the request has just made. The actor defaults to the signed-in user and the
+// IP address is taken from the request. A failure to record is logged, and counted in /debug/info,
+// rather than failing the request, since the write itself has already happened. Services that record
+// the entry in the write's own transaction take auditContext instead.
+func recordAudit(r *http.Request, event models.AuditEvent) {
+	if err := services.RecordAudit(withAuditContext(r, event)); err != nil {
+		log.Println("Error recording audit entry, the entry is lost:", event.Action, event.EntityType, event.EntityID, err)
+	}
+}
+
+// auditContext is who made the request and from where, for services that write the audit entry themselves
+func auditContext(r *http.Request) models.AuditEvent {
+	return withAuditContext(r, models.AuditEvent{})
+}
+
+func withAuditContext(r *http.Request, event models.AuditEvent) models.AuditEvent {
+	if event.ActorID == 0 {
+		if user := middleware.GetUserFromContext(r); user != nil {
+			event.ActorID = user.UserID
+		}
+	}
+	event.IPAddress = utils.ClientIP(r)
+
+	// Note which key acted, so a leaked key's activity can be traced
+	if key := middleware.GetAPIKeyFromContext(r); key != nil && event.Details == nil {
+		event.Details = map[string]interface{}{"apiKeyId": key.KeyID, "apiKeyName": key.Name}
+	}
+	return event
+}
+
+// bookingStadiumID finds the stadium a booking belongs to so its audit entries show up in the owner's log
+func bookingStadiumID(booking *models.Booking) int {
+	if booking == nil {
+		return 0
+	}
+	arena, err := services.GetArenaByID(booking.ArenaID)
+	if err != nil {
+		return 0
+	}
+	return arena.StadiumID
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"BookMyArena/backend/authz"
 	"BookMyArena/backend/middleware"
 	"BookMyArena/backend/models"
 	"BookMyArena/backend/services"
@@ -12,7 +13,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func CreateBooking(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -36,12 +37,20 @@ func CreateBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionBookingCreated,
+		EntityType: services.AuditEntityBooking,
+		EntityID:   booking.BookingID,
+		StadiumID:  bookingStadiumID(booking),
+		After:      booking,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(booking)
 }
 
-func GetBookings(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetBookings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -55,7 +64,7 @@ func GetBookings(w http.ResponseWriter, r *http.Request) {
 
 	var bookings interface{}
 
-	if user.Role == "Owner" {
+	if services.Authorize(user, authz.StadiumOwn) {
 		ownerBookings, err := services.GetOwnerBookings(user.UserID)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
@@ -81,7 +90,7 @@ func GetBookings(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(bookings)
 }
 
-func CancelBooking(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -100,7 +109,7 @@ func CancelBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = services.CancelBooking(bookingID, user.UserID)
+	err = services.CancelBooking(h.deps, bookingID, user.UserID, auditContext(r))
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
@@ -110,15 +119,15 @@ func CancelBooking(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "booking cancelled successfully"})
 }
 
-func UpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) UpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r)
-	if user == nil || user.Role != "Owner" {
-		utils.RespondWithError(w, http.StatusForbidden, "owner access required")
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -137,7 +146,7 @@ func UpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = services.UpdateBookingStatus(bookingID, req.Status, user.UserID)
+	err = services.UpdateBookingStatus(h.deps, bookingID, req.Status, user.UserID, auditContext(r))
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
@@ -146,3 +155,97 @@ func UpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "booking status updated successfully"})
 }
+
+func (h *Handlers) CreateWalkInBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.WalkInBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	booking, err := services.CreateWalkInBooking(user.UserID, req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionBookingWalkIn,
+		EntityType: services.AuditEntityBooking,
+		EntityID:   booking.BookingID,
+		StadiumID:  bookingStadiumID(booking),
+		After:      booking,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(booking)
+}
+
+func (h *Handlers) CheckInBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookingID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid booking ID")
+		return
+	}
+
+	err = services.CheckInBooking(bookingID, user.UserID, auditContext(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "booking checked in successfully"})
+}
+
+func (h *Handlers) GetStadiumBookings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Access is checked at the route (booking:view on the stadium)
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	bookings, err := services.GetStadiumBookings(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if bookings == nil {
+		bookings = []models.BookingWithDetails{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookings)
+}
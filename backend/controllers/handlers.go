@@ -0,0 +1,12 @@
+package controllers
+
+import "BookMyArena/backend/services"
+
+// Handlers serves the API routes. deps carries the settings the services behind them depend on.
+type Handlers struct {
+	deps *services.Deps
+}
+
+func NewHandlers(deps *services.Deps) *Handlers {
+	return &Handlers{deps: deps}
+}
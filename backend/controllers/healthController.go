@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
+	"encoding/json"
+	"net/http"
+)
+
+// Healthz is the liveness probe: it answers as long as the process can serve HTTP at all, and
+// touches nothing else, so a database outage doesn't get the instance restarted.
+func (h *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Readyz is the readiness probe. It answers 503 while this instance can't serve traffic, so the
+// load balancer sends requests elsewhere until it recovers.
+func (h *Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := services.CheckReadiness(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetDebugInfo returns build, uptime, database pool and background job details
+func (h *Handlers) GetDebugInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(services.GetDebugInfo(r.Context()))
+}
@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"BookMyArena/backend/middleware"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handlers) InviteStadiumMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	var req models.InviteStadiumMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Email == "" || req.Role == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "email and role are required")
+		return
+	}
+
+	member, err := services.InviteStadiumMember(stadiumID, user.UserID, req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionMemberInvited,
+		EntityType: services.AuditEntityMembership,
+		EntityID:   member.MemberID,
+		StadiumID:  stadiumID,
+		After:      member,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handlers) GetStadiumMembers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	members, err := services.GetStadiumMembers(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if members == nil {
+		members = []models.StadiumMemberWithDetails{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+func (h *Handlers) UpdateStadiumMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+	memberID, err := strconv.Atoi(vars["memberId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid member ID")
+		return
+	}
+
+	var req models.UpdateStadiumMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before, _ := services.GetStadiumMemberByID(memberID)
+
+	err = services.UpdateStadiumMemberRole(stadiumID, memberID, req.Role)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetStadiumMemberByID(memberID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionMemberRoleChanged,
+		EntityType: services.AuditEntityMembership,
+		EntityID:   memberID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      after,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "member role updated successfully"})
+}
+
+func (h *Handlers) RemoveStadiumMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+	memberID, err := strconv.Atoi(vars["memberId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid member ID")
+		return
+	}
+
+	member, err := services.GetStadiumMemberByID(memberID)
+	if err != nil || member.StadiumID != stadiumID {
+		utils.RespondWithError(w, http.StatusNotFound, "membership not found")
+		return
+	}
+
+	err = services.RemoveStadiumMember(memberID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionMemberRemoved,
+		EntityType: services.AuditEntityMembership,
+		EntityID:   memberID,
+		StadiumID:  member.StadiumID,
+		Before:     member,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "member removed successfully"})
+}
+
+func (h *Handlers) GetMyMemberships(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	memberships, err := services.GetMembershipsByUser(user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if memberships == nil {
+		memberships = []models.StadiumMemberWithDetails{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(memberships)
+}
+
+func (h *Handlers) AcceptMembership(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	memberID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid membership ID")
+		return
+	}
+
+	before, _ := services.GetStadiumMemberByID(memberID)
+
+	err = services.AcceptStadiumInvite(memberID, user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, err := services.GetStadiumMemberByID(memberID)
+	if err == nil {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionMemberAccepted,
+			EntityType: services.AuditEntityMembership,
+			EntityID:   memberID,
+			StadiumID:  after.StadiumID,
+			Before:     before,
+			After:      after,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "invite accepted"})
+}
+
+// LeaveMembership declines a pending invite or leaves a stadium's staff
+func (h *Handlers) LeaveMembership(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	memberID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid membership ID")
+		return
+	}
+
+	member, err := services.GetStadiumMemberByID(memberID)
+	if err != nil || member.UserID != user.UserID {
+		utils.RespondWithError(w, http.StatusNotFound, "membership not found")
+		return
+	}
+
+	err = services.RemoveStadiumMember(memberID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionMemberLeft,
+		EntityType: services.AuditEntityMembership,
+		EntityID:   memberID,
+		StadiumID:  member.StadiumID,
+		Before:     member,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "membership removed"})
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"BookMyArena/backend/middleware"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc/"
+
+	// The provider redirects back here; the login page picks up the session cookie or the challenge
+	oidcLoginPage = "/frontend/pages/login.html"
+)
+
+func (h *Handlers) GetOIDCProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.GetOIDCProviders())
+}
+
+// BeginOIDCLogin redirects the browser to the provider's sign-in page
+func (h *Handlers) BeginOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	providerName := mux.Vars(r)["provider"]
+	rememberMe := r.URL.Query().Get("rememberMe") == "true"
+
+	authURL, state, err := services.BeginOIDCLogin(r.Context(), providerName, rememberMe)
+	if err != nil {
+		redirectToLogin(w, r, url.Values{"error": {err.Error()}})
+		return
+	}
+
+	// The state is also bound to this browser so a callback URL started elsewhere can't log it in.
+	// Lax, because the provider's redirect back is a cross-site navigation.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   utils.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback finishes a provider login and sends the browser back to the login page
+func (h *Handlers) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	providerName := mux.Vars(r)["provider"]
+	query := r.URL.Query()
+	state := query.Get("state")
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   utils.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if query.Get("error") != "" {
+		redirectToLogin(w, r, url.Values{"error": {"sign-in was cancelled or refused by the provider"}})
+		return
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		redirectToLogin(w, r, url.Values{"error": {"login has expired, please try again"}})
+		return
+	}
+
+	result, err := services.CompleteOIDCLogin(h.deps, r.Context(), providerName, state, query.Get("code"))
+	if err != nil {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionUserLoginFailed,
+			EntityType: services.AuditEntityUser,
+			Details:    map[string]string{"provider": providerName, "error": err.Error()},
+		})
+		redirectToLogin(w, r, url.Values{"error": {err.Error()}})
+		return
+	}
+
+	user := result.User
+	details := map[string]string{"provider": providerName}
+
+	switch {
+	case result.Created:
+		recordAudit(r, models.AuditEvent{
+			ActorID:    user.UserID,
+			Action:     services.AuditActionUserSignup,
+			EntityType: services.AuditEntityUser,
+			EntityID:   user.UserID,
+			After:      user,
+			Details:    details,
+		})
+	case result.Linked:
+		recordAudit(r, models.AuditEvent{
+			ActorID:    user.UserID,
+			Action:     services.AuditActionIdentityLinked,
+			EntityType: services.AuditEntityUser,
+			EntityID:   user.UserID,
+			Details:    details,
+		})
+	}
+
+	if result.ChallengeToken != "" {
+		recordAudit(r, models.AuditEvent{
+			ActorID:    user.UserID,
+			Action:     services.AuditActionLoginChallenge,
+			EntityType: services.AuditEntityUser,
+			EntityID:   user.UserID,
+			Details:    details,
+		})
+
+		fragment := url.Values{"challenge": {result.ChallengeToken}}
+		if result.RememberMe {
+			fragment.Set("rememberMe", "true")
+		}
+		redirectToLogin(w, r, fragment)
+		return
+	}
+
+	if _, err := h.createLoginSession(w, r, user, result.RememberMe, details); err != nil {
+		redirectToLogin(w, r, url.Values{"error": {"failed to create session"}})
+		return
+	}
+
+	redirectToLogin(w, r, nil)
+}
+
+func (h *Handlers) GetUserIdentities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	identities, err := services.GetUserIdentities(user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if identities == nil {
+		identities = []models.UserIdentity{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(identities)
+}
+
+// redirectToLogin passes results in the fragment so challenge tokens and messages stay out of server logs
+func redirectToLogin(w http.ResponseWriter, r *http.Request, fragment url.Values) {
+	target := oidcLoginPage
+	if len(fragment) > 0 {
+		target += "#" + fragment.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
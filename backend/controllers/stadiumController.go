@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"BookMyArena/backend/authz"
 	"BookMyArena/backend/middleware"
 	"BookMyArena/backend/models"
 	"BookMyArena/backend/services"
@@ -12,7 +13,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func CreateStadium(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) CreateStadium(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -41,12 +42,20 @@ func CreateStadium(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumCreated,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadium.StadiumID,
+		StadiumID:  stadium.StadiumID,
+		After:      stadium,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(stadium)
 }
 
-func GetStadiums(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetStadiums(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -62,7 +71,7 @@ func GetStadiums(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	// If user is owner, return only their stadiums; otherwise return all
-	if user.Role == "Owner" {
+	if services.Authorize(user, authz.StadiumOwn) {
 		stadiums, err = services.GetStadiumsByOwner(user.UserID)
 	} else {
 		stadiums, err = services.GetAllStadiums()
@@ -77,7 +86,7 @@ func GetStadiums(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stadiums)
 }
 
-func GetStadium(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetStadium(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -96,7 +105,388 @@ func GetStadium(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Stadiums that aren't listed are only visible to their own staff and to admins
+	if !services.IsStadiumListed(stadium) {
+		user := middleware.GetUserFromContext(r)
+		if user == nil || (!services.AuthorizeStadium(user.UserID, stadiumID, authz.StadiumView) && !services.Authorize(user, authz.ListingModerate)) {
+			utils.RespondWithError(w, http.StatusNotFound, "stadium not found")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stadium)
+}
+
+func (h *Handlers) UpdateStadium(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	var req models.CreateStadiumRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Name == "" || req.Location == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "name and location are required")
+		return
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	stadium, err := services.UpdateStadium(stadiumID, req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumUpdated,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      stadium,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stadium)
+}
+
+// UpdateStadiumSecurity lets the owner require two-factor authentication from the stadium's staff
+func (h *Handlers) UpdateStadiumSecurity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	var req models.StadiumSecurityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	stadium, err := services.SetStadiumStaffTwoFactor(stadiumID, user.UserID, req.RequireStaffTwoFactor)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumSecurity,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      stadium,
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stadium)
 }
 
+func (h *Handlers) DeleteStadium(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	err = services.DeleteStadium(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumDeleted,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "stadium deleted successfully"})
+}
+
+func (h *Handlers) RequestStadiumTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	var req models.CreateStadiumTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.ToEmail == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "receiving owner email is required")
+		return
+	}
+
+	transfer, err := services.RequestStadiumTransfer(stadiumID, user.UserID, req.ToEmail)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionTransferRequested,
+		EntityType: services.AuditEntityTransfer,
+		EntityID:   transfer.TransferID,
+		StadiumID:  stadiumID,
+		After:      transfer,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+func (h *Handlers) GetStadiumTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	transfers, err := services.GetStadiumTransfersForUser(user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if transfers == nil {
+		transfers = []models.StadiumTransferWithDetails{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+func (h *Handlers) AcceptStadiumTransfer(w http.ResponseWriter, r *http.Request) {
+	respondToStadiumTransfer(w, r, true)
+}
+
+func (h *Handlers) DeclineStadiumTransfer(w http.ResponseWriter, r *http.Request) {
+	respondToStadiumTransfer(w, r, false)
+}
+
+func respondToStadiumTransfer(w http.ResponseWriter, r *http.Request, accept bool) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	transferID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid transfer ID")
+		return
+	}
+
+	before, err := services.GetStadiumTransferByID(transferID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	err = services.RespondToStadiumTransfer(transferID, user.UserID, accept)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "transfer declined"
+	action := services.AuditActionTransferDeclined
+	if accept {
+		message = "transfer accepted"
+		action = services.AuditActionTransferAccepted
+	}
+
+	after, _ := services.GetStadiumTransferByID(transferID)
+	recordAudit(r, models.AuditEvent{
+		Action:     action,
+		EntityType: services.AuditEntityTransfer,
+		EntityID:   transferID,
+		StadiumID:  before.StadiumID,
+		Before:     before,
+		After:      after,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func (h *Handlers) CancelStadiumTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	transferID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid transfer ID")
+		return
+	}
+
+	before, err := services.GetStadiumTransferByID(transferID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	err = services.CancelStadiumTransfer(transferID, user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after, _ := services.GetStadiumTransferByID(transferID)
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionTransferCancelled,
+		EntityType: services.AuditEntityTransfer,
+		EntityID:   transferID,
+		StadiumID:  before.StadiumID,
+		Before:     before,
+		After:      after,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "transfer cancelled"})
+}
+
+func (h *Handlers) SubmitStadiumForReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	stadiumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+		return
+	}
+
+	before, err := services.GetStadiumByID(stadiumID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	stadium, err := services.SubmitStadiumForReview(stadiumID, user.UserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionStadiumSubmitted,
+		EntityType: services.AuditEntityStadium,
+		EntityID:   stadiumID,
+		StadiumID:  stadiumID,
+		Before:     before,
+		After:      stadium,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stadium)
+}
@@ -6,11 +6,15 @@ import (
 	"BookMyArena/backend/services"
 	"BookMyArena/backend/utils"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/gorilla/mux"
 )
 
-func Signup(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) Signup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -34,18 +38,26 @@ func Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate password strength (minimum 6 characters)
-	if len(req.Password) < 6 {
-		utils.RespondWithError(w, http.StatusBadRequest, "password must be at least 6 characters")
+	// Validate password strength
+	if err := services.ValidatePassword(h.deps, req.Password); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	user, err := services.CreateUser(req)
+	user, err := services.CreateUser(h.deps, req)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	recordAudit(r, models.AuditEvent{
+		ActorID:    user.UserID,
+		Action:     services.AuditActionUserSignup,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+		After:      user,
+	})
+
 	// Don't send password hash
 	user.PasswordHash = ""
 	w.Header().Set("Content-Type", "application/json")
@@ -53,7 +65,7 @@ func Signup(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(user)
 }
 
-func Login(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -71,63 +83,436 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := services.AuthenticateUser(req.Email, req.Password)
+	ipAddress := utils.ClientIP(r)
+
+	retryAfter, err := services.LoginRetryAfter(req.Email, ipAddress)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "failed to log in")
+		return
+	}
+	if retryAfter > 0 {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionUserLoginFailed,
+			EntityType: services.AuditEntityUser,
+			Details:    map[string]string{"email": req.Email, "error": "throttled"},
+		})
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		utils.RespondWithError(w, http.StatusTooManyRequests, "too many failed login attempts, please try again later")
+		return
+	}
+
+	user, challengeToken, err := services.AuthenticateUser(h.deps, req.Email, req.Password, ipAddress)
 	if err != nil {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionUserLoginFailed,
+			EntityType: services.AuditEntityUser,
+			Details:    map[string]string{"email": req.Email, "error": err.Error()},
+		})
 		utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	// Create session
-	token, err := services.CreateSession(user.UserID)
+	// Accounts with two-factor authentication get a short-lived challenge instead of a session
+	if challengeToken != "" {
+		recordAudit(r, models.AuditEvent{
+			ActorID:    user.UserID,
+			Action:     services.AuditActionLoginChallenge,
+			EntityType: services.AuditEntityUser,
+			EntityID:   user.UserID,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+		})
+		return
+	}
+
+	h.startSession(w, r, user, req.RememberMe, nil)
+}
+
+// LoginTwoFactor finishes a login started by Login for an account with two-factor authentication
+func (h *Handlers) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req models.LoginChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.ChallengeToken == "" || req.Code == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "challengeToken and code are required")
+		return
+	}
+
+	ipAddress := utils.ClientIP(r)
+
+	retryAfter, err := services.LoginChallengeRetryAfter(req.ChallengeToken, ipAddress)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "failed to log in")
+		return
+	}
+	if retryAfter > 0 {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionUserLoginFailed,
+			EntityType: services.AuditEntityUser,
+			Details:    map[string]string{"stage": "two_factor", "error": "throttled"},
+		})
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		utils.RespondWithError(w, http.StatusTooManyRequests, "too many failed login attempts, please try again later")
+		return
+	}
+
+	user, method, err := services.CompleteLoginChallenge(h.deps, req.ChallengeToken, req.Code, ipAddress)
+	if err != nil {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionUserLoginFailed,
+			EntityType: services.AuditEntityUser,
+			Details:    map[string]string{"stage": "two_factor", "error": err.Error()},
+		})
+		utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	h.startSession(w, r, user, req.RememberMe, map[string]string{"secondFactor": method})
+}
+
+// startSession creates a session for an authenticated user, sets the cookie and writes the login response
+func (h *Handlers) startSession(w http.ResponseWriter, r *http.Request, user *models.User, rememberMe bool, details interface{}) {
+	session, err := h.createLoginSession(w, r, user, rememberMe, details)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
 
-	// Set cookie
-	http.SetCookie(w, &http.Cookie{
+	// Don't send password hash
+	user.PasswordHash = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user":      user,
+		"token":     session.Token,
+		"expiresAt": session.ExpiresAt,
+	})
+}
+
+// createLoginSession creates the session, records the login and sets the session cookie
+func (h *Handlers) createLoginSession(w http.ResponseWriter, r *http.Request, user *models.User, rememberMe bool, details interface{}) (*models.Session, error) {
+	session, err := services.CreateSession(h.deps, user.UserID, models.SessionOptions{
+		UserAgent:  r.UserAgent(),
+		IPAddress:  utils.ClientIP(r),
+		RememberMe: rememberMe,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(r, models.AuditEvent{
+		ActorID:    user.UserID,
+		Action:     services.AuditActionUserLogin,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+		Details:    details,
+	})
+
+	// Set cookie. Without "remember me" it is a browser-session cookie; the server-side idle and
+	// absolute timeouts apply either way. Lax keeps it off cross-site subrequests and form posts while
+	// still signing the user in when they follow a link to the site; CSRFProtect covers the rest.
+	cookie := &http.Cookie{
 		Name:     "session_token",
-		Value:    token,
-		Expires:  time.Now().Add(24 * time.Hour),
+		Value:    session.Token,
 		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
+		Secure:   utils.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
 		Path:     "/",
+	}
+	if session.RememberMe {
+		cookie.Expires = session.AbsoluteExpiresAt
+	}
+	http.SetCookie(w, cookie)
+
+	if err := middleware.SetCSRFCookie(w, r); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if token := middleware.SessionTokenFromRequest(r); token != "" {
+		services.DeleteSession(token)
+	}
+
+	if user := middleware.GetUserFromContext(r); user != nil {
+		recordAudit(r, models.AuditEvent{
+			Action:     services.AuditActionUserLogout,
+			EntityType: services.AuditEntityUser,
+			EntityID:   user.UserID,
+		})
+	}
+
+	clearSessionCookie(w, r)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "logged out successfully"})
+}
+
+func (h *Handlers) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := services.VerifyEmail(req.Token)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		ActorID:    user.UserID,
+		Action:     services.AuditActionUserEmailVerified,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
 	})
 
-	// Don't send password hash
-	user.PasswordHash = ""
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"user":  user,
-		"token": token,
+	json.NewEncoder(w).Encode(map[string]string{"message": "email verified"})
+}
+
+func (h *Handlers) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := services.ResendVerificationEmail(h.deps, user.UserID); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "verification email sent"})
+}
+
+func (h *Handlers) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := services.RequestPasswordReset(h.deps, req.Email); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Same answer whether or not the account exists
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "if that email has an account, a reset link is on its way"})
+}
+
+func (h *Handlers) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := services.ResetPassword(h.deps, req.Token, req.Password)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		ActorID:    user.UserID,
+		Action:     services.AuditActionUserPasswordReset,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
 	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "password reset, please log in"})
 }
 
-func Logout(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err := services.ChangePassword(h.deps, user.UserID, middleware.SessionTokenFromRequest(r), req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionUserPasswordChange,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "password changed, other sessions were signed out"})
+}
+
+// GetPasswordPolicy tells the signup and password forms what a new password needs
+func (h *Handlers) GetPasswordPolicy(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	cookie, err := r.Cookie("session_token")
-	if err == nil {
-		services.DeleteSession(cookie.Value)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.GetPasswordPolicy(h.deps))
+}
+
+func (h *Handlers) GetSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
 	}
 
-	// Clear cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_token",
-		Value:    "",
-		Expires:  time.Now().Add(-time.Hour),
-		HttpOnly: true,
-		Path:     "/",
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	sessions, err := services.GetUserSessions(user.UserID, middleware.SessionTokenFromRequest(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+func (h *Handlers) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	if err := services.RevokeSession(user.UserID, sessionID); err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionSessionRevoked,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+		Details:    map[string]int{"sessionId": sessionID},
 	})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "logged out successfully"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "session revoked"})
 }
 
-func GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+// RevokeAllSessions signs the user out of their other sessions, or of every session
+// including this one with ?includeCurrent=true
+func (h *Handlers) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r)
+	if user == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	keepToken := middleware.SessionTokenFromRequest(r)
+	includeCurrent := r.URL.Query().Get("includeCurrent") == "true"
+	if includeCurrent {
+		keepToken = ""
+	}
+
+	if err := services.DeleteUserSessions(user.UserID, keepToken); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recordAudit(r, models.AuditEvent{
+		Action:     services.AuditActionSessionRevoked,
+		EntityType: services.AuditEntityUser,
+		EntityID:   user.UserID,
+		Details:    map[string]bool{"all": true, "includeCurrent": includeCurrent},
+	})
+
+	if includeCurrent {
+		clearSessionCookie(w, r)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "sessions revoked"})
+}
+
+func (h *Handlers) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUserFromContext(r)
 	if user == nil {
 		utils.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
@@ -139,6 +524,18 @@ func GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(user)
 }
 
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_token",
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HttpOnly: true,
+		Secure:   utils.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && contains(s[1:], substr) || s[:len(substr)] == substr)
 }
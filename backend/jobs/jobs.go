@@ -0,0 +1,214 @@
+// Package jobs runs the server's background work: periodic jobs such as session cleanup, and
+// one-off tasks started by requests that shouldn't hold up the response. Everything it starts is
+// tracked, so Shutdown can stop the schedule and wait for work in progress before the database
+// pool is closed underneath it.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Status describes one periodic job. The times are zero until the job first runs.
+type Status struct {
+	Name         string
+	Interval     time.Duration
+	Running      bool
+	Runs         int
+	LastStarted  time.Time
+	LastFinished time.Time
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func()
+
+	// Guarded by mu
+	scheduledAt  time.Time
+	running      bool
+	runs         int
+	lastStarted  time.Time
+	lastFinished time.Time
+}
+
+var (
+	mu       sync.Mutex
+	jobs     []*job
+	started  bool
+	stopping bool
+	stop     = make(chan struct{})
+	running  sync.WaitGroup
+
+	// drained is closed once everything running at shutdown has returned
+	drained chan struct{}
+)
+
+// Every schedules run to be called every interval, starting one interval after Start. A run that
+// is still going when the next is due delays it rather than overlapping.
+func Every(name string, interval time.Duration, run func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	j := &job{name: name, interval: interval, run: run}
+	jobs = append(jobs, j)
+	if started && !stopping {
+		schedule(j)
+	}
+}
+
+// Start begins running the scheduled jobs. Jobs added with Every afterwards start right away.
+func Start() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if started {
+		return
+	}
+	started = true
+	for _, j := range jobs {
+		schedule(j)
+	}
+}
+
+// schedule starts j's loop; mu must be held
+func schedule(j *job) {
+	j.scheduledAt = time.Now()
+	running.Add(1)
+	go func() {
+		defer running.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				j.runOnce()
+			}
+		}
+	}()
+}
+
+func (j *job) runOnce() {
+	mu.Lock()
+	j.running = true
+	j.lastStarted = time.Now()
+	mu.Unlock()
+
+	runSafely(j.name, j.run)
+
+	mu.Lock()
+	j.running = false
+	j.runs++
+	j.lastFinished = time.Now()
+	mu.Unlock()
+}
+
+// Go runs task in the background. Once shutdown has begun new tasks are dropped, since the
+// resources they need may already be gone.
+func Go(name string, task func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if stopping {
+		log.Printf("Skipping background task %s: shutting down\n", name)
+		return
+	}
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		runSafely(name, task)
+	}()
+}
+
+// Done is closed when shutdown begins. Tasks that run for long, such as retry loops, should
+// return once it is.
+func Done() <-chan struct{} {
+	return stop
+}
+
+// Statuses lists the periodic jobs in the order they were added
+func Statuses() []Status {
+	mu.Lock()
+	defer mu.Unlock()
+
+	statuses := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		statuses = append(statuses, Status{
+			Name:         j.name,
+			Interval:     j.interval,
+			Running:      j.running,
+			Runs:         j.runs,
+			LastStarted:  j.lastStarted,
+			LastFinished: j.lastFinished,
+		})
+	}
+	return statuses
+}
+
+// Healthy reports an error if the scheduler isn't running or a job has missed its schedule: nothing
+// has finished for two intervals, which means a run is stuck.
+func Healthy() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !started {
+		return errors.New("background jobs not started")
+	}
+	if stopping {
+		return errors.New("background jobs stopped")
+	}
+	now := time.Now()
+	for _, j := range jobs {
+		last := j.scheduledAt
+		if j.lastFinished.After(last) {
+			last = j.lastFinished
+		}
+		if now.Sub(last) > 2*j.interval {
+			return fmt.Errorf("background job %s is overdue, it should finish every %s", j.name, j.interval)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops the schedule and waits for running jobs and tasks to return. It gives up when
+// ctx is done, returning ctx's error; the abandoned work keeps running until the process exits.
+// Calling it again waits for the same work.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	if !stopping {
+		stopping = true
+		close(stop)
+
+		drained = make(chan struct{})
+		go func() {
+			running.Wait()
+			close(drained)
+		}()
+	}
+	done := drained
+	mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runSafely keeps a panicking job from taking the server down with it
+func runSafely(name string, run func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Background job %s panicked: %v\n", name, r)
+		}
+	}()
+	run()
+}
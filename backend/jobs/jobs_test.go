@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// resetForTest gives each test a fresh scheduler, shutting down whatever the test left running.
+// running is left alone: it is back to zero once Shutdown has waited for everything.
+func resetForTest(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		jobs = nil
+		started = false
+		stopping = false
+		stop = make(chan struct{})
+		drained = nil
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEveryRunsAfterStart(t *testing.T) {
+	resetForTest(t)
+
+	var runs atomic.Int32
+	Every("counter", 5*time.Millisecond, func() { runs.Add(1) })
+
+	time.Sleep(20 * time.Millisecond)
+	if n := runs.Load(); n != 0 {
+		t.Fatalf("job ran %d times before Start", n)
+	}
+	if err := Healthy(); err == nil || !strings.Contains(err.Error(), "not started") {
+		t.Errorf("Healthy() before Start = %v, want not started", err)
+	}
+
+	Start()
+	Start() // a second call doesn't schedule the jobs twice
+	waitFor(t, "three runs", func() bool { return runs.Load() >= 3 })
+	if err := Healthy(); err != nil {
+		t.Errorf("Healthy() while running = %v", err)
+	}
+
+	statuses := Statuses()
+	if len(statuses) != 1 {
+		t.Fatalf("Statuses() has %d jobs, want 1", len(statuses))
+	}
+	status := statuses[0]
+	if status.Name != "counter" || status.Interval != 5*time.Millisecond || status.Runs < 3 {
+		t.Errorf("status = %+v, want counter every 5ms with at least 3 runs", status)
+	}
+	if status.LastStarted.IsZero() || status.LastFinished.IsZero() {
+		t.Errorf("status = %+v, want start and finish times", status)
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-Done():
+	default:
+		t.Error("Done() is still open after Shutdown")
+	}
+	if err := Healthy(); err == nil || !strings.Contains(err.Error(), "stopped") {
+		t.Errorf("Healthy() after Shutdown = %v, want stopped", err)
+	}
+
+	after := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	if n := runs.Load(); n != after {
+		t.Errorf("job ran %d more times after Shutdown", n-after)
+	}
+}
+
+func TestEveryAfterStart(t *testing.T) {
+	resetForTest(t)
+	Start()
+
+	var runs atomic.Int32
+	Every("late", 5*time.Millisecond, func() { runs.Add(1) })
+	waitFor(t, "a run of a job added after Start", func() bool { return runs.Load() > 0 })
+}
+
+func TestPanickingJobKeepsRunning(t *testing.T) {
+	resetForTest(t)
+
+	var runs atomic.Int32
+	Every("panics", 5*time.Millisecond, func() {
+		runs.Add(1)
+		panic("boom")
+	})
+	Start()
+	waitFor(t, "a run after the first panic", func() bool { return runs.Load() >= 2 })
+
+	var ran atomic.Bool
+	Go("panicking task", func() { panic("boom") })
+	Go("task after it", func() { ran.Store(true) })
+	waitFor(t, "the next task", ran.Load)
+}
+
+func TestShutdownWaitsForTasks(t *testing.T) {
+	resetForTest(t)
+	Start()
+
+	release := make(chan struct{})
+	var finished atomic.Bool
+	Go("slow task", func() {
+		<-release
+		finished.Store(true)
+	})
+
+	// Shutdown gives up at the deadline while the task is still going
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown with a stuck task = %v, want DeadlineExceeded", err)
+	}
+
+	// New work is refused once shutdown has begun
+	var late atomic.Bool
+	Go("late task", func() { late.Store(true) })
+
+	close(release)
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown after the task returned: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Shutdown returned before the task finished")
+	}
+	if late.Load() {
+		t.Error("a task started after Shutdown ran")
+	}
+}
+
+func TestHealthyDetectsStuckJobs(t *testing.T) {
+	const interval = time.Minute
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		scheduledAt  time.Time
+		lastFinished time.Time
+		running      bool
+		wantErr      bool
+	}{
+		{name: "waiting for its first run", scheduledAt: now.Add(-interval / 2)},
+		{name: "first run overdue", scheduledAt: now.Add(-3 * interval), wantErr: true},
+		{name: "finished recently", scheduledAt: now.Add(-time.Hour), lastFinished: now.Add(-interval)},
+		{name: "running on schedule", scheduledAt: now.Add(-time.Hour), lastFinished: now.Add(-interval - time.Second), running: true},
+		{name: "stuck in a run", scheduledAt: now.Add(-time.Hour), lastFinished: now.Add(-3 * interval), running: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetForTest(t)
+
+			// Set the job's state directly rather than waiting for minutes-long intervals
+			mu.Lock()
+			started = true
+			jobs = []*job{
+				{name: "healthy", interval: time.Hour, scheduledAt: now},
+				{name: "cleanup", interval: interval, scheduledAt: tt.scheduledAt, lastFinished: tt.lastFinished, running: tt.running},
+			}
+			mu.Unlock()
+
+			err := Healthy()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "cleanup is overdue") {
+					t.Errorf("Healthy() = %v, want cleanup overdue", err)
+				}
+			} else if err != nil {
+				t.Errorf("Healthy() = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Package mail delivers transactional email through a pluggable Sender. Production uses SMTP;
+// development can capture messages in a local outbox (files or a database table) or just log them.
+package mail
+
+import (
+	"BookMyArena/backend/config"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers one message. Implementations must be safe for concurrent use.
+type Sender interface {
+	Send(msg Message) error
+}
+
+var (
+	sender Sender = LogSender{}
+	from          = "BookMyArena <no-reply@bookmyarena.local>"
+)
+
+// Init picks the sender from settings.Transport: "smtp", "file", "db" or "log".
+// db is only used by the "db" outbox.
+func Init(settings config.MailSettings, db *sql.DB) error {
+	switch settings.Transport {
+	case "smtp":
+		smtpSender, err := NewSMTPSender(settings)
+		if err != nil {
+			return fmt.Errorf("configuring SMTP mail: %v", err)
+		}
+		sender = smtpSender
+	case "file":
+		sender = FileOutbox{Dir: settings.OutboxDir}
+	case "db":
+		sender = DBOutbox{DB: db}
+	case "log":
+		sender = LogSender{}
+	default:
+		return fmt.Errorf("unknown mail transport %q", settings.Transport)
+	}
+
+	if settings.From != "" {
+		from = settings.From
+	}
+	log.Printf("Mail transport: %s\n", settings.Transport)
+	return nil
+}
+
+// SetSender replaces the active sender
+func SetSender(s Sender) {
+	sender = s
+}
+
+func Send(msg Message) error {
+	if msg.To == "" {
+		return errors.New("message has no recipient")
+	}
+	return sender.Send(msg)
+}
+
+// From is the sender address used in outgoing messages
+func From() string {
+	return from
+}
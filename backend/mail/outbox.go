@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// LogSender writes messages to the server log. Useful when running locally without any mail setup.
+type LogSender struct{}
+
+func (LogSender) Send(msg Message) error {
+	log.Printf("Mail to %s: %s\n%s\n", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileOutbox writes each message to its own .eml file in Dir, which most mail clients can open
+type FileOutbox struct {
+	Dir string
+}
+
+var outboxSeq uint64
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (o FileOutbox) Send(msg Message) error {
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d-%s.eml", now.Format("20060102-150405"), atomic.AddUint64(&outboxSeq, 1),
+		unsafeFileChars.ReplaceAllString(msg.To, "_"))
+
+	return os.WriteFile(filepath.Join(o.Dir, name), formatMessage(msg, now), 0o644)
+}
+
+// DBOutbox stores messages in the MailOutbox table for inspection or delivery by a separate worker
+type DBOutbox struct {
+	DB *sql.DB
+}
+
+func (o DBOutbox) Send(msg Message) error {
+	_, err := o.DB.Exec(
+		"INSERT INTO MailOutbox (Recipient, Subject, Body) VALUES (@p1, @p2, @p3)",
+		msg.To, msg.Subject, msg.Body,
+	)
+	return err
+}
@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"BookMyArena/backend/config"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSender delivers messages through an SMTP relay, authenticating with PLAIN when a username is set
+type SMTPSender struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+}
+
+func NewSMTPSender(settings config.MailSettings) (*SMTPSender, error) {
+	if settings.SMTPHost == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+
+	return &SMTPSender{
+		Addr:     net.JoinHostPort(settings.SMTPHost, strconv.Itoa(settings.SMTPPort)),
+		Host:     settings.SMTPHost,
+		Username: settings.SMTPUsername,
+		Password: settings.SMTPPassword,
+	}, nil
+}
+
+func (s *SMTPSender) Send(msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	return smtp.SendMail(s.Addr, auth, envelopeAddress(From()), []string{msg.To}, formatMessage(msg, time.Now()))
+}
+
+// formatMessage renders a plain-text RFC 5322 message
+func formatMessage(msg Message, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", From())
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// headerValue keeps user-supplied text from starting a new header line
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// envelopeAddress extracts the bare address from "Name <address>"
+func envelopeAddress(from string) string {
+	if start := strings.LastIndex(from, "<"); start >= 0 {
+		if end := strings.LastIndex(from, ">"); end > start {
+			return from[start+1 : end]
+		}
+	}
+	return from
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"BookMyArena/backend/authz"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// apiKeyRoute marks a route handler as usable with an API key that holds scope
+type apiKeyRoute struct {
+	scope authz.APIKeyScope
+	next  http.Handler
+}
+
+func (h apiKeyRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+// AllowAPIKey opens a route to API keys with the scope. It must be the outermost wrapper on the
+// route's handler, since AuthMiddleware finds it there; sessions are unaffected.
+func AllowAPIKey(scope authz.APIKeyScope, next http.Handler) http.Handler {
+	return apiKeyRoute{scope: scope, next: next}
+}
+
+func apiKeyScopeFor(r *http.Request) (authz.APIKeyScope, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+
+	handler, ok := route.GetHandler().(apiKeyRoute)
+	if !ok {
+		return "", false
+	}
+	return handler.scope, true
+}
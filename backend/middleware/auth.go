@@ -3,6 +3,7 @@ package middleware
 import (
 	"BookMyArena/backend/models"
 	"BookMyArena/backend/services"
+	"BookMyArena/backend/utils"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -10,52 +11,108 @@ import (
 
 type contextKey string
 
-const UserContextKey contextKey = "user"
-
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get session token from cookie or Authorization header
-		var token string
-		
-		// Try cookie first
-		cookie, err := r.Cookie("session_token")
-		if err == nil {
-			token = cookie.Value
-		} else {
-			// Try Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-				token = authHeader[7:]
+const (
+	UserContextKey   contextKey = "user"
+	APIKeyContextKey contextKey = "apiKey"
+)
+
+// API keys may also be sent in this header instead of as a Bearer token
+const apiKeyHeader = "X-API-Key"
+
+const sessionCookieName = "session_token"
+
+func AuthMiddleware(deps *services.Deps) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := apiKeyFromRequest(r); key != "" {
+				authenticateAPIKey(w, r, key, next)
+				return
 			}
-		}
-
-		if token == "" {
-			respondWithError(w, http.StatusUnauthorized, "unauthorized: no session token")
-			return
-		}
-
-		// Validate session
-		user, err := services.ValidateSession(token)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, "unauthorized: invalid or expired session")
-			return
-		}
-
-		// Add user to context
-		ctx := context.WithValue(r.Context(), UserContextKey, user)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+
+			token := SessionTokenFromRequest(r)
+			if token == "" {
+				respondWithError(w, http.StatusUnauthorized, "unauthorized: no session token")
+				return
+			}
+
+			// Validate session
+			user, err := services.ValidateSession(deps, token)
+			if err != nil {
+				respondWithError(w, http.StatusUnauthorized, "unauthorized: invalid or expired session")
+				return
+			}
+
+			// Add user to context
+			ctx := context.WithValue(r.Context(), UserContextKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
-func OwnerOnlyMiddleware(next http.Handler) http.Handler {
-	return AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user := r.Context().Value(UserContextKey).(*models.User)
-		if user.Role != "Owner" {
-			respondWithError(w, http.StatusForbidden, "forbidden: owner access required")
-			return
-		}
-		next.ServeHTTP(w, r)
-	}))
+// authenticateAPIKey lets a key through only to routes wrapped in AllowAPIKey with one of its scopes.
+// Everything else, including key management itself, needs a real session.
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, key string, next http.Handler) {
+	user, apiKey, err := services.ValidateAPIKey(key, utils.ClientIP(r))
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized: "+err.Error())
+		return
+	}
+
+	scope, ok := apiKeyScopeFor(r)
+	if !ok {
+		respondWithError(w, http.StatusForbidden, "forbidden: this endpoint can't be used with an API key")
+		return
+	}
+	if !services.APIKeyHasScope(apiKey, scope) {
+		respondWithError(w, http.StatusForbidden, "forbidden: API key is missing scope "+string(scope))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserContextKey, user)
+	ctx = context.WithValue(ctx, APIKeyContextKey, apiKey)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// SessionTokenFromRequest returns the session token from the Authorization header or, failing that, the cookie.
+// The header wins so a request that carries one is never authenticated by the cookie (see CSRFProtect).
+func SessionTokenFromRequest(r *http.Request) string {
+	if token := bearerToken(r); token != "" {
+		return token
+	}
+
+	cookie, err := r.Cookie(sessionCookieName)
+	if err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// apiKeyFromRequest returns an API key from the X-API-Key header or a Bearer token with the key prefix
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	if token := bearerToken(r); services.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+func bearerToken(r *http.Request) string {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		return authHeader[7:]
+	}
+	return ""
+}
+
+// GetAPIKeyFromContext returns the API key that authenticated the request, or nil for a session
+func GetAPIKeyFromContext(r *http.Request) *models.APIKey {
+	key, ok := r.Context().Value(APIKeyContextKey).(*models.APIKey)
+	if !ok {
+		return nil
+	}
+	return key
 }
 
 func GetUserFromContext(r *http.Request) *models.User {
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// RequirePermission only lets the request through if the user's account role grants the permission.
+// It must run after AuthMiddleware.
+func RequirePermission(permission authz.Permission, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := GetUserFromContext(r)
+		if user == nil {
+			respondWithError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		if !services.Authorize(user, permission) {
+			respondWithError(w, http.StatusForbidden, "forbidden: missing permission "+string(permission))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireStadiumPermission checks the permission against the stadium named by the route variable idVar,
+// e.g. RequireStadiumPermission(authz.StaffManage, "id", ...) on /stadiums/{id}/members.
+func RequireStadiumPermission(permission authz.Permission, idVar string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := GetUserFromContext(r)
+		if user == nil {
+			respondWithError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		stadiumID, err := strconv.Atoi(mux.Vars(r)[idVar])
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid stadium ID")
+			return
+		}
+
+		if !services.AuthorizeStadium(user.UserID, stadiumID, permission) {
+			respondWithError(w, http.StatusForbidden, "forbidden: missing permission "+string(permission)+" on this stadium")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireVerifiedEmail blocks actions that other people rely on, such as listing a stadium or holding
+// a slot, until the account has confirmed its email address. It wraps a handler so it can sit inside
+// RequirePermission.
+func RequireVerifiedEmail(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := GetUserFromContext(r)
+		if user == nil {
+			respondWithError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		if !user.EmailVerified {
+			respondWithError(w, http.StatusForbidden, "verify your email address first")
+			return
+		}
+
+		next(w, r)
+	}
+}
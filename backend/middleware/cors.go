@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Origins lists the origins other than our own that may call the API from a browser, e.g. a
+// separately hosted frontend
+type Origins map[string]bool
+
+// NewOrigins builds the cross-origin allowlist. Entries are origins like https://app.example.com.
+func NewOrigins(origins []string) Origins {
+	allowed := Origins{}
+	for _, origin := range origins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			allowed[strings.ToLower(origin)] = true
+		}
+	}
+	return allowed
+}
+
+// Allowed reports whether a browser origin is this server itself or on the allowlist
+func (allowed Origins) Allowed(r *http.Request, origin string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	return allowed[strings.ToLower(parsed.Scheme+"://"+parsed.Host)]
+}
+
+// CORS answers preflight requests and lets allowlisted origins read responses, with credentials.
+// Other origins get no CORS headers, so browsers keep their responses from the calling page.
+func CORS(allowedOrigins Origins) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && allowedOrigins.Allowed(r, origin)
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+CSRFHeaderName+", "+apiKeyHeader)
+			}
+
+			if r.Method == http.MethodOptions {
+				if origin != "" && !allowed {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"BookMyArena/backend/utils"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+)
+
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// CSRFProtect guards state-changing requests that a browser could send on a user's behalf from
+// another site. Unsafe methods are refused when the Origin (or Referer) names a site that isn't
+// allowed. If the session cookie is present, the request must also echo the csrf_token cookie
+// in the X-CSRF-Token header, which only pages on an allowed origin can read (double submit).
+// Requests with a Bearer token or an X-API-Key header are exempt: browsers never attach those
+// headers by themselves, and when present they are what authenticates the request.
+func CSRFProtect(allowedOrigins Origins) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				// Hand out the token early so the first form submission already has it
+				if _, err := r.Cookie(CSRFCookieName); err != nil {
+					if err := SetCSRFCookie(w, r); err != nil {
+						respondWithError(w, http.StatusInternalServerError, "failed to create CSRF token")
+						return
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if origin := requestOrigin(r); origin != "" && !allowedOrigins.Allowed(r, origin) {
+				respondWithError(w, http.StatusForbidden, "forbidden: cross-site request")
+				return
+			}
+
+			if bearerToken(r) != "" || r.Header.Get(apiKeyHeader) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Without the session cookie there are no ambient credentials to abuse
+			if _, err := r.Cookie(sessionCookieName); err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cookie, err := r.Cookie(CSRFCookieName)
+			header := r.Header.Get(CSRFHeaderName)
+			if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+				respondWithError(w, http.StatusForbidden, "forbidden: missing or invalid CSRF token")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SetCSRFCookie issues a fresh token. Login calls it so a token planted before sign-in isn't kept.
+// The cookie is readable by scripts on purpose: the page copies it into the request header.
+func SetCSRFCookie(w http.ResponseWriter, r *http.Request) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(raw),
+		Path:     "/",
+		Secure:   utils.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// requestOrigin is the Origin header, falling back to the origin part of the Referer.
+// An opaque "null" origin is returned as is and never matches.
+func requestOrigin(r *http.Request) string {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return origin
+	}
+
+	referer, err := url.Parse(r.Header.Get("Referer"))
+	if err != nil || referer.Host == "" {
+		return ""
+	}
+	return referer.Scheme + "://" + referer.Host
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"BookMyArena/backend/utils"
+	"net"
+	"net/http"
+)
+
+// ProxyHeaders works out each request's client address and scheme, believing forwarding headers
+// only from the trusted proxies. It must run before anything that calls utils.ClientIP or
+// utils.IsSecureRequest.
+func ProxyHeaders(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, utils.ResolveClient(r, trusted))
+		})
+	}
+}
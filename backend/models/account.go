@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// UpdateProfileRequest changes the signed-in user's name and, optionally, their email. A new email
+// only takes effect once confirmed from that address; Password is required to start the change.
+type UpdateProfileRequest struct {
+	FullName string `json:"fullName"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ProfileUpdateResult struct {
+	User         *User  `json:"user"`
+	PendingEmail string `json:"pendingEmail,omitempty"`
+}
+
+// DeleteAccountRequest confirms an account deletion. Password is ignored for accounts that only
+// sign in through an external provider; Code is needed when two-factor authentication is on.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// AccountExport is everything held about a user, returned by the personal data export
+type AccountExport struct {
+	ExportedAt    time.Time                  `json:"exportedAt"`
+	User          AccountExportUser          `json:"user"`
+	Sessions      []Session                  `json:"sessions"`
+	Bookings      []BookingWithDetails       `json:"bookings"`
+	Identities    []UserIdentity             `json:"identities"`
+	Memberships   []StadiumMemberWithDetails `json:"memberships"`
+	SavedSearches []SavedSearch              `json:"savedSearches"`
+	Notifications []Notification             `json:"notifications"`
+	APIKeys       []APIKey                   `json:"apiKeys"`
+
+	// The moderation status and admin notes of the stadium listings the user owns. These are
+	// decisions about the user's submissions, not reviews written by customers; the app has none.
+	ListingModeration []StadiumReview `json:"listingModeration"`
+}
+
+type AccountExportUser struct {
+	User
+	PendingEmail    string     `json:"pendingEmail,omitempty"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+type UserSearchParams struct {
+	Query      string
+	Role       string
+	Status     string
+	PageNumber int
+	PageSize   int
+}
+
+type PaginatedUsers struct {
+	Users      []User `json:"users"`
+	TotalCount int    `json:"totalCount"`
+	PageNumber int    `json:"pageNumber"`
+	PageSize   int    `json:"pageSize"`
+	TotalPages int    `json:"totalPages"`
+}
+
+type BookingSearchParams struct {
+	BookingID int
+	UserID    int
+	StadiumID int
+	ArenaID   int
+	Status    string
+	Limit     int
+}
+
+type ModerationRequest struct {
+	Reason string `json:"reason"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type APIKey struct {
+	KeyID      int        `json:"keyId" db:"KeyId"`
+	UserID     int        `json:"userId" db:"UserId"`
+	Name       string     `json:"name" db:"Name"`
+	Prefix     string     `json:"prefix" db:"KeyPrefix"`
+	Scopes     []string   `json:"scopes" db:"Scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt" db:"ExpiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt" db:"LastUsedAt"`
+	LastUsedIP string     `json:"lastUsedIp" db:"LastUsedIp"`
+	RevokedAt  *time.Time `json:"revokedAt" db:"RevokedAt"`
+	CreatedAt  time.Time  `json:"createdAt" db:"CreatedAt"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIKey is returned once, when the key is created. The full key can't be retrieved later.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
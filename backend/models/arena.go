@@ -9,9 +9,12 @@ type Arena struct {
 	StadiumID    int       `json:"stadiumId" db:"StadiumId"`
 	Name         string    `json:"name" db:"Name"`
 	SportType    string    `json:"sportType" db:"SportType"`
+	SportTypeID  int       `json:"sportTypeId" db:"SportTypeId"`
+	Description  string    `json:"description" db:"Description"`
 	Capacity     int       `json:"capacity" db:"Capacity"`
 	SlotDuration int       `json:"slotDuration" db:"SlotDuration"`
 	Price        float64   `json:"price" db:"Price"`
+	IsPublished  bool      `json:"isPublished" db:"IsPublished"`
 	CreatedAt    time.Time `json:"createdAt" db:"CreatedAt"`
 }
 
@@ -25,6 +28,8 @@ type CreateArenaRequest struct {
 	StadiumID    int     `json:"stadiumId"`
 	Name         string  `json:"name"`
 	SportType    string  `json:"sportType"`
+	SportTypeID  int     `json:"sportTypeId"`
+	Description  string  `json:"description"`
 	Capacity     int     `json:"capacity"`
 	SlotDuration int     `json:"slotDuration"`
 	Price        float64 `json:"price"`
@@ -52,3 +57,26 @@ type PaginatedArenas struct {
 	PageSize   int     `json:"pageSize"`
 	TotalPages int     `json:"totalPages"`
 }
+
+type SlotSearchParams struct {
+	SportType string
+	Location  string
+	StadiumID int
+	ArenaID   int
+	Duration  int
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+type AvailableSlot struct {
+	ArenaID     int       `json:"arenaId"`
+	ArenaName   string    `json:"arenaName"`
+	StadiumID   int       `json:"stadiumId"`
+	StadiumName string    `json:"stadiumName"`
+	Location    string    `json:"location"`
+	SportType   string    `json:"sportType"`
+	Price       float64   `json:"price"`
+	SlotStart   time.Time `json:"slotStart"`
+	SlotEnd     time.Time `json:"slotEnd"`
+}
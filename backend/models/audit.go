@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntry struct {
+	AuditID    int             `json:"auditId" db:"AuditId"`
+	ActorID    int             `json:"actorId" db:"ActorId"`
+	Action     string          `json:"action" db:"Action"`
+	EntityType string          `json:"entityType" db:"EntityType"`
+	EntityID   int             `json:"entityId" db:"EntityId"`
+	StadiumID  int             `json:"stadiumId,omitempty" db:"StadiumId"`
+	Changes    json.RawMessage `json:"changes,omitempty" db:"Changes"`
+	Details    json.RawMessage `json:"details,omitempty" db:"Details"`
+	IPAddress  string          `json:"ipAddress,omitempty" db:"IpAddress"`
+	CreatedAt  time.Time       `json:"createdAt" db:"CreatedAt"`
+}
+
+// AuditEvent describes a write to be recorded. Before and After are snapshots of the entity
+// and are diffed field by field; either may be nil for creates and deletes.
+type AuditEvent struct {
+	ActorID    int
+	IPAddress  string
+	Action     string
+	EntityType string
+	EntityID   int
+	StadiumID  int
+	Before     interface{}
+	After      interface{}
+	Details    interface{}
+}
+
+// FieldChange is one entry of an audit diff
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type AuditLogParams struct {
+	ActorID    int
+	EntityType string
+	EntityID   int
+	StadiumID  int
+	OwnerID    int
+	Action     string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
@@ -5,13 +5,16 @@ import (
 )
 
 type Booking struct {
-	BookingID int       `json:"bookingId" db:"BookingId"`
-	UserID    int       `json:"userId" db:"UserId"`
-	ArenaID   int       `json:"arenaId" db:"ArenaId"`
-	SlotStart time.Time `json:"slotStart" db:"SlotStart"`
-	SlotEnd   time.Time `json:"slotEnd" db:"SlotEnd"`
-	Status    string    `json:"status" db:"Status"`
-	CreatedAt time.Time `json:"createdAt" db:"CreatedAt"`
+	BookingID          int        `json:"bookingId" db:"BookingId"`
+	UserID             int        `json:"userId" db:"UserId"`
+	ArenaID            int        `json:"arenaId" db:"ArenaId"`
+	SlotStart          time.Time  `json:"slotStart" db:"SlotStart"`
+	SlotEnd            time.Time  `json:"slotEnd" db:"SlotEnd"`
+	Status             string     `json:"status" db:"Status"`
+	WalkInName         string     `json:"walkInName,omitempty" db:"WalkInName"`
+	CheckedInAt        *time.Time `json:"checkedInAt,omitempty" db:"CheckedInAt"`
+	CancellationReason string     `json:"cancellationReason,omitempty" db:"CancellationReason"`
+	CreatedAt          time.Time  `json:"createdAt" db:"CreatedAt"`
 }
 
 type CreateBookingRequest struct {
@@ -20,6 +23,13 @@ type CreateBookingRequest struct {
 	SlotEnd   time.Time `json:"slotEnd"`
 }
 
+type WalkInBookingRequest struct {
+	ArenaID      int       `json:"arenaId"`
+	SlotStart    time.Time `json:"slotStart"`
+	SlotEnd      time.Time `json:"slotEnd"`
+	CustomerName string    `json:"customerName"`
+}
+
 type BookingWithDetails struct {
 	Booking
 	ArenaName   string  `json:"arenaName"`
@@ -28,4 +38,3 @@ type BookingWithDetails struct {
 	SportType   string  `json:"sportType"`
 	Price       float64 `json:"price"`
 }
-
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// HealthCheck is the outcome of one readiness check
+type HealthCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// ReadinessReport is returned by /readyz. Ready is true only when every check passed.
+type ReadinessReport struct {
+	Ready  bool          `json:"ready"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// DebugInfo is the diagnostics snapshot returned to admins by /debug/info
+type DebugInfo struct {
+	Version       string            `json:"version"`
+	Revision      string            `json:"revision,omitempty"`
+	GoVersion     string            `json:"goVersion"`
+	StartedAt     time.Time         `json:"startedAt"`
+	UptimeSeconds int64             `json:"uptimeSeconds"`
+	Goroutines    int               `json:"goroutines"`
+	Database      DatabaseDebugInfo `json:"database"`
+	Jobs          []JobDebugInfo    `json:"jobs"`
+
+	// AuditFailures counts audit entries lost since startup because they couldn't be written
+	AuditFailures int64 `json:"auditFailures"`
+}
+
+// DatabaseDebugInfo reports the connection state and the connection pool's counters
+type DatabaseDebugInfo struct {
+	Available             bool   `json:"available"`
+	SchemaVersion         int    `json:"schemaVersion"`
+	ExpectedSchemaVersion int    `json:"expectedSchemaVersion"`
+	Error                 string `json:"error,omitempty"`
+	MaxOpenConnections    int    `json:"maxOpenConnections"`
+	OpenConnections       int    `json:"openConnections"`
+	InUse                 int    `json:"inUse"`
+	Idle                  int    `json:"idle"`
+	WaitCount             int64  `json:"waitCount"`
+	WaitDurationMs        int64  `json:"waitDurationMs"`
+	MaxIdleClosed         int64  `json:"maxIdleClosed"`
+	MaxIdleTimeClosed     int64  `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed     int64  `json:"maxLifetimeClosed"`
+}
+
+// JobDebugInfo is the state of one periodic background job. The last-run fields are left out until
+// the job has started or finished a run.
+type JobDebugInfo struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Running        bool       `json:"running"`
+	Runs           int        `json:"runs"`
+	LastStarted    *time.Time `json:"lastStarted,omitempty"`
+	LastFinished   *time.Time `json:"lastFinished,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs,omitempty"`
+}
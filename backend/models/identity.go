@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserIdentity links an account to a login at an external OpenID Connect provider
+type UserIdentity struct {
+	IdentityID  int        `json:"identityId" db:"IdentityId"`
+	UserID      int        `json:"userId" db:"UserId"`
+	Provider    string     `json:"provider" db:"Provider"`
+	Subject     string     `json:"-" db:"Subject"`
+	Email       string     `json:"email" db:"Email"`
+	CreatedAt   time.Time  `json:"createdAt" db:"CreatedAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt" db:"LastLoginAt"`
+}
+
+// OIDCProvider is what the login page needs to show a sign-in button
+type OIDCProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// OIDCLoginResult is the outcome of a completed provider login. ChallengeToken is set instead of
+// creating a session when the account uses two-factor authentication.
+type OIDCLoginResult struct {
+	User           *User
+	Provider       string
+	RememberMe     bool
+	Created        bool
+	Linked         bool
+	ChallengeToken string
+}
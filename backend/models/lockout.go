@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AccountLockout records one temporary login block. Scope is "account" (ThrottleKey is the email
+// that was tried) or "ip" (ThrottleKey is the client address). UserID is set when the email
+// belongs to an account.
+type AccountLockout struct {
+	LockoutID   int        `json:"lockoutId" db:"LockoutId"`
+	Scope       string     `json:"scope" db:"Scope"`
+	ThrottleKey string     `json:"throttleKey" db:"ThrottleKey"`
+	UserID      *int       `json:"userId" db:"UserId"`
+	Failures    int        `json:"failures" db:"Failures"`
+	LockedAt    time.Time  `json:"lockedAt" db:"LockedAt"`
+	LockedUntil time.Time  `json:"lockedUntil" db:"LockedUntil"`
+	ClearedAt   *time.Time `json:"clearedAt" db:"ClearedAt"`
+	ClearedBy   *int       `json:"clearedBy" db:"ClearedBy"`
+	Active      bool       `json:"active"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+type StadiumMember struct {
+	MemberID   int        `json:"memberId" db:"MemberId"`
+	StadiumID  int        `json:"stadiumId" db:"StadiumId"`
+	UserID     int        `json:"userId" db:"UserId"`
+	Role       string     `json:"role" db:"Role"`
+	Status     string     `json:"status" db:"Status"`
+	InvitedBy  int        `json:"invitedBy" db:"InvitedBy"`
+	CreatedAt  time.Time  `json:"createdAt" db:"CreatedAt"`
+	AcceptedAt *time.Time `json:"acceptedAt" db:"AcceptedAt"`
+}
+
+type StadiumMemberWithDetails struct {
+	StadiumMember
+	FullName    string `json:"fullName"`
+	Email       string `json:"email"`
+	StadiumName string `json:"stadiumName"`
+}
+
+type InviteStadiumMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type UpdateStadiumMemberRequest struct {
+	Role string `json:"role"`
+}
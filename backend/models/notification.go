@@ -0,0 +1,16 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Notification struct {
+	NotificationID int             `json:"notificationId" db:"NotificationId"`
+	UserID         int             `json:"userId" db:"UserId"`
+	Type           string          `json:"type" db:"Type"`
+	Message        string          `json:"message" db:"Message"`
+	Data           json.RawMessage `json:"data,omitempty" db:"Data"`
+	ReadAt         *time.Time      `json:"readAt" db:"ReadAt"`
+	CreatedAt      time.Time       `json:"createdAt" db:"CreatedAt"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+type SavedSearch struct {
+	SavedSearchID  int        `json:"savedSearchId" db:"SavedSearchId"`
+	UserID         int        `json:"userId" db:"UserId"`
+	Name           string     `json:"name" db:"Name"`
+	SportType      string     `json:"sportType" db:"SportType"`
+	Location       string     `json:"location" db:"Location"`
+	StadiumID      int        `json:"stadiumId,omitempty" db:"StadiumId"`
+	Duration       int        `json:"duration" db:"Duration"`
+	WindowStart    time.Time  `json:"windowStart" db:"WindowStart"`
+	WindowEnd      time.Time  `json:"windowEnd" db:"WindowEnd"`
+	LastNotifiedAt *time.Time `json:"lastNotifiedAt" db:"LastNotifiedAt"`
+	CreatedAt      time.Time  `json:"createdAt" db:"CreatedAt"`
+}
+
+type CreateSavedSearchRequest struct {
+	Name        string    `json:"name"`
+	SportType   string    `json:"sportType"`
+	Location    string    `json:"location"`
+	StadiumID   int       `json:"stadiumId"`
+	Duration    int       `json:"duration"`
+	WindowStart time.Time `json:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd"`
+}
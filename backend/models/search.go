@@ -0,0 +1,20 @@
+package models
+
+type SearchResult struct {
+	Type        string  `json:"type"`
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	StadiumID   int     `json:"stadiumId"`
+	StadiumName string  `json:"stadiumName,omitempty"`
+	Location    string  `json:"location"`
+	SportType   string  `json:"sportType,omitempty"`
+	Price       float64 `json:"price,omitempty"`
+	Score       float64 `json:"score"`
+}
+
+type AutocompleteSuggestion struct {
+	Text  string  `json:"text"`
+	Type  string  `json:"type"`
+	ID    int     `json:"id,omitempty"`
+	Score float64 `json:"score"`
+}
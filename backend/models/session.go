@@ -5,9 +5,22 @@ import (
 )
 
 type Session struct {
-	SessionID int       `json:"sessionId" db:"SessionId"`
-	UserID    int       `json:"userId" db:"UserId"`
-	Token     string    `json:"token" db:"Token"`
-	ExpiresAt time.Time `json:"expiresAt" db:"ExpiresAt"`
+	SessionID         int       `json:"sessionId" db:"SessionId"`
+	UserID            int       `json:"userId" db:"UserId"`
+	Token             string    `json:"-" db:"Token"`
+	UserAgent         string    `json:"userAgent" db:"UserAgent"`
+	IPAddress         string    `json:"ipAddress" db:"IpAddress"`
+	RememberMe        bool      `json:"rememberMe" db:"RememberMe"`
+	CreatedAt         time.Time `json:"createdAt" db:"CreatedAt"`
+	LastSeenAt        time.Time `json:"lastSeenAt" db:"LastSeenAt"`
+	ExpiresAt         time.Time `json:"expiresAt" db:"ExpiresAt"`
+	AbsoluteExpiresAt time.Time `json:"absoluteExpiresAt" db:"AbsoluteExpiresAt"`
+	Current           bool      `json:"current"`
 }
 
+// SessionOptions describes the client a session is created for
+type SessionOptions struct {
+	UserAgent  string
+	IPAddress  string
+	RememberMe bool
+}
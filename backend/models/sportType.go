@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+type SportType struct {
+	SportTypeID int       `json:"sportTypeId" db:"SportTypeId"`
+	Name        string    `json:"name" db:"Name"`
+	ParentID    *int      `json:"parentId" db:"ParentId"`
+	Aliases     []string  `json:"aliases"`
+	CreatedAt   time.Time `json:"createdAt" db:"CreatedAt"`
+}
@@ -5,15 +5,50 @@ import (
 )
 
 type Stadium struct {
-	StadiumID int       `json:"stadiumId" db:"StadiumId"`
-	OwnerID   int       `json:"ownerId" db:"OwnerId"`
-	Name      string    `json:"name" db:"Name"`
-	Location  string    `json:"location" db:"Location"`
-	CreatedAt time.Time `json:"createdAt" db:"CreatedAt"`
+	StadiumID             int        `json:"stadiumId" db:"StadiumId"`
+	OwnerID               int        `json:"ownerId" db:"OwnerId"`
+	Name                  string     `json:"name" db:"Name"`
+	Location              string     `json:"location" db:"Location"`
+	Description           string     `json:"description" db:"Description"`
+	IsPublished           bool       `json:"isPublished" db:"IsPublished"`
+	Status                string     `json:"status" db:"Status"`
+	ReviewNote            string     `json:"reviewNote,omitempty" db:"ReviewNote"`
+	SubmittedAt           *time.Time `json:"submittedAt,omitempty" db:"SubmittedAt"`
+	ReviewedAt            *time.Time `json:"reviewedAt,omitempty" db:"ReviewedAt"`
+	RequireStaffTwoFactor bool       `json:"requireStaffTwoFactor" db:"RequireStaffTwoFactor"`
+	CreatedAt             time.Time  `json:"createdAt" db:"CreatedAt"`
 }
 
 type CreateStadiumRequest struct {
-	Name     string `json:"name"`
-	Location string `json:"location"`
+	Name        string `json:"name"`
+	Location    string `json:"location"`
+	Description string `json:"description"`
 }
 
+type StadiumTransfer struct {
+	TransferID  int        `json:"transferId" db:"TransferId"`
+	StadiumID   int        `json:"stadiumId" db:"StadiumId"`
+	FromOwnerID int        `json:"fromOwnerId" db:"FromOwnerId"`
+	ToOwnerID   int        `json:"toOwnerId" db:"ToOwnerId"`
+	Status      string     `json:"status" db:"Status"`
+	CreatedAt   time.Time  `json:"createdAt" db:"CreatedAt"`
+	RespondedAt *time.Time `json:"respondedAt" db:"RespondedAt"`
+}
+
+type StadiumTransferWithDetails struct {
+	StadiumTransfer
+	StadiumName    string `json:"stadiumName"`
+	FromOwnerEmail string `json:"fromOwnerEmail"`
+	ToOwnerEmail   string `json:"toOwnerEmail"`
+}
+
+type CreateStadiumTransferRequest struct {
+	ToEmail string `json:"toEmail"`
+}
+
+type StadiumReview struct {
+	Stadium
+	OwnerName  string `json:"ownerName"`
+	OwnerEmail string `json:"ownerEmail"`
+	ArenaCount int    `json:"arenaCount"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// TwoFactorSetup is returned when enrollment starts. The secret is shown so it can be typed in
+// by hand if the QR code can't be scanned.
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// DisableTwoFactorRequest turns two-factor authentication off. Password is ignored for accounts that
+// only sign in through an external provider.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// LoginChallengeRequest completes a login for an account with two-factor authentication.
+// Code is either a TOTP code or one of the account's recovery codes.
+type LoginChallengeRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	RememberMe     bool   `json:"rememberMe"`
+}
+
+type StadiumSecurityRequest struct {
+	RequireStaffTwoFactor bool `json:"requireStaffTwoFactor"`
+}
@@ -5,12 +5,15 @@ import (
 )
 
 type User struct {
-	UserID       int       `json:"userId" db:"UserId"`
-	FullName     string    `json:"fullName" db:"FullName"`
-	Email        string    `json:"email" db:"Email"`
-	PasswordHash string    `json:"-" db:"PasswordHash"`
-	Role         string    `json:"role" db:"Role"`
-	CreatedAt    time.Time `json:"createdAt" db:"CreatedAt"`
+	UserID           int       `json:"userId" db:"UserId"`
+	FullName         string    `json:"fullName" db:"FullName"`
+	Email            string    `json:"email" db:"Email"`
+	PasswordHash     string    `json:"-" db:"PasswordHash"`
+	Role             string    `json:"role" db:"Role"`
+	Status           string    `json:"status" db:"Status"`
+	EmailVerified    bool      `json:"emailVerified" db:"EmailVerified"`
+	TwoFactorEnabled bool      `json:"twoFactorEnabled" db:"TwoFactorEnabled"`
+	CreatedAt        time.Time `json:"createdAt" db:"CreatedAt"`
 }
 
 type SignupRequest struct {
@@ -20,8 +23,29 @@ type SignupRequest struct {
 	Role     string `json:"role"`
 }
 
-type LoginRequest struct {
-	Email    string `json:"email"`
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
 	Password string `json:"password"`
 }
 
+// ChangePasswordRequest sets a new password. Code stands in for CurrentPassword on an account that
+// has none yet but has two-factor authentication on.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	Code            string `json:"code"`
+}
+
+type LoginRequest struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	RememberMe bool   `json:"rememberMe"`
+}
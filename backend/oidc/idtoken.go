@@ -0,0 +1,327 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Clock drift allowed when checking exp and iat
+const leeway = time.Minute
+
+// Unknown key IDs trigger a JWKS refresh, but no more often than this
+const minKeyRefresh = time.Minute
+
+// Claims are the ID token claims used to find or create the local account
+type Claims struct {
+	Issuer        string     `json:"iss"`
+	Subject       string     `json:"sub"`
+	Audience      audience   `json:"aud"`
+	AuthorizedBy  string     `json:"azp"`
+	Expiry        int64      `json:"exp"`
+	IssuedAt      int64      `json:"iat"`
+	Nonce         string     `json:"nonce"`
+	Email         string     `json:"email"`
+	EmailVerified stringBool `json:"email_verified"`
+	Name          string     `json:"name"`
+	GivenName     string     `json:"given_name"`
+	FamilyName    string     `json:"family_name"`
+}
+
+// audience accepts both forms of the aud claim: a single string or an array
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// stringBool accepts true/false as JSON booleans or strings, as some providers send email_verified as "true"
+type stringBool bool
+
+func (b *stringBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// VerifyIDToken checks the signature against the provider's JWKS and validates issuer, audience,
+// expiry and nonce. Only asymmetric algorithms are accepted.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*Claims, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("id token is malformed")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("id token header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("id token signature is malformed")
+	}
+
+	key, err := p.signingKey(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("id token claims: %w", err)
+	}
+
+	metadata, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != metadata.Issuer:
+		return nil, fmt.Errorf("id token issuer %q does not match %q", claims.Issuer, metadata.Issuer)
+	case !claims.Audience.contains(p.ClientID):
+		return nil, errors.New("id token was not issued for this client")
+	case len(claims.Audience) > 1 && claims.AuthorizedBy != "" && claims.AuthorizedBy != p.ClientID:
+		return nil, errors.New("id token was authorized for another client")
+	case claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(leeway)):
+		return nil, errors.New("id token has expired")
+	case claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(leeway)):
+		return nil, errors.New("id token was issued in the future")
+	case claims.Nonce != nonce:
+		return nil, errors.New("id token nonce does not match")
+	case claims.Subject == "":
+		return nil, errors.New("id token has no subject")
+	}
+
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+type keySet struct {
+	keys      []jsonWebKey
+	fetchedAt time.Time
+}
+
+// signingKey finds the key for a token, refreshing the cached JWKS once if the key ID is new to us
+// so providers can rotate keys without a restart
+func (p *Provider) signingKey(ctx context.Context, header tokenHeader) (crypto.PublicKey, error) {
+	if _, ok := algorithms[header.Algorithm]; !ok {
+		return nil, fmt.Errorf("id token algorithm %q is not supported", header.Algorithm)
+	}
+
+	metadata, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys == nil {
+		if err := p.refreshKeys(ctx, metadata.JWKSURI); err != nil {
+			return nil, err
+		}
+	} else if _, ok := p.keys.find(header); !ok && time.Since(p.keys.fetchedAt) > minKeyRefresh {
+		if err := p.refreshKeys(ctx, metadata.JWKSURI); err != nil {
+			return nil, err
+		}
+	}
+
+	jwk, ok := p.keys.find(header)
+	if !ok {
+		return nil, errors.New("id token is signed with an unknown key")
+	}
+	return jwk.publicKey()
+}
+
+// refreshKeys must be called with p.mu held
+func (p *Provider) refreshKeys(ctx context.Context, jwksURI string) error {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return fmt.Errorf("fetching signing keys: %w", err)
+	}
+	p.keys = &keySet{keys: set.Keys, fetchedAt: time.Now()}
+	return nil
+}
+
+func (s *keySet) find(header tokenHeader) (jsonWebKey, bool) {
+	keyType := algorithms[header.Algorithm].keyType
+
+	var match jsonWebKey
+	matches := 0
+	for _, key := range s.keys {
+		if key.KeyType != keyType || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+			continue
+		}
+		if header.KeyID != "" {
+			if key.KeyID == header.KeyID {
+				return key, true
+			}
+			continue
+		}
+		match = key
+		matches++
+	}
+
+	// Without a kid the key is only unambiguous if there is exactly one candidate
+	return match, header.KeyID == "" && matches == 1
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("rsa key exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec key point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("key parameter is malformed")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+type algorithm struct {
+	keyType string
+	hash    crypto.Hash
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {"RSA", crypto.SHA256},
+	"RS384": {"RSA", crypto.SHA384},
+	"RS512": {"RSA", crypto.SHA512},
+	"ES256": {"EC", crypto.SHA256},
+	"ES384": {"EC", crypto.SHA384},
+	"ES512": {"EC", crypto.SHA512},
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	hasher := algorithms[alg].hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, algorithms[alg].hash, digest, signature); err != nil {
+			return errors.New("id token signature is invalid")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("id token signature is invalid")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("id token signature is invalid")
+		}
+		return nil
+	}
+	return errors.New("unsupported key type")
+}
@@ -1,61 +1,164 @@
 package routes
 
 import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
 	"BookMyArena/backend/controllers"
 	"BookMyArena/backend/middleware"
+	"BookMyArena/backend/services"
 	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes() *mux.Router {
+// SetupRoutes builds the router. The settings and deps are passed on to the middleware and handlers
+// that need them.
+func SetupRoutes(settings *config.Settings, deps *services.Deps) *mux.Router {
 	r := mux.NewRouter()
+	h := controllers.NewHandlers(deps)
 
-	// CORS middleware
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			if req.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-			next.ServeHTTP(w, req)
-		})
-	})
+	// Client addresses and HTTPS come from forwarding headers only when a trusted proxy sent them
+	r.Use(middleware.ProxyHeaders(settings.Server.TrustedProxies))
+
+	// Cross-origin access is limited to the configured origins; the bundled frontend is same-origin.
+	// CSRFProtect runs on every route so the public login and signup endpoints are covered too.
+	allowedOrigins := middleware.NewOrigins(settings.CORS.AllowedOrigins)
+	r.Use(middleware.CORS(allowedOrigins))
+	r.Use(middleware.CSRFProtect(allowedOrigins))
+
+	// Health checks for load balancers and orchestrators
+	r.HandleFunc("/healthz", h.Healthz).Methods("GET", "HEAD")
+	r.HandleFunc("/readyz", h.Readyz).Methods("GET", "HEAD")
 
 	// Public routes
-	r.HandleFunc("/api/signup", controllers.Signup).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/login", controllers.Login).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/signup", h.Signup).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/login", h.Login).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/login/2fa", h.LoginTwoFactor).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/auth/oidc/providers", h.GetOIDCProviders).Methods("GET", "OPTIONS")
+	r.HandleFunc("/api/auth/oidc/{provider}/login", h.BeginOIDCLogin).Methods("GET", "OPTIONS")
+	r.HandleFunc("/api/auth/oidc/{provider}/callback", h.OIDCCallback).Methods("GET", "OPTIONS")
+	r.HandleFunc("/api/verify-email", h.VerifyEmail).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/forgot-password", h.ForgotPassword).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/reset-password", h.ResetPassword).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/password-policy", h.GetPasswordPolicy).Methods("GET", "OPTIONS")
+	r.HandleFunc("/api/user/email/confirm", h.ConfirmEmailChange).Methods("POST", "OPTIONS")
 
-	// Protected routes - require authentication
+	// Protected routes - require authentication. Routes that need more than a signed-in user declare
+	// their permission here; stadium-scoped ones name the route variable holding the stadium ID.
+	// RequireVerifiedEmail guards the actions an unverified account may not take yet.
+	// Arena and booking routes resolve the stadium from the record, so those checks live in the services.
+	// AllowAPIKey marks the routes an API key may call and the scope it needs; all others refuse keys.
 	api := r.PathPrefix("/api").Subrouter()
-	api.Use(middleware.AuthMiddleware)
+	api.Use(middleware.AuthMiddleware(deps))
 
 	// User routes
-	api.HandleFunc("/logout", controllers.Logout).Methods("GET", "OPTIONS")
-	api.HandleFunc("/user", controllers.GetCurrentUser).Methods("GET", "OPTIONS")
+	api.HandleFunc("/logout", h.Logout).Methods("POST", "OPTIONS")
+	api.HandleFunc("/user", h.GetCurrentUser).Methods("GET", "OPTIONS")
+	api.HandleFunc("/user", h.UpdateProfile).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/user", h.DeleteAccount).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/user/export", h.ExportAccountData).Methods("GET", "OPTIONS")
+	api.HandleFunc("/user/password", h.ChangePassword).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/sessions", h.GetSessions).Methods("GET", "OPTIONS")
+	api.HandleFunc("/sessions", h.RevokeAllSessions).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/sessions/{id}", h.RevokeSession).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/verify-email/resend", h.ResendVerificationEmail).Methods("POST", "OPTIONS")
+	api.HandleFunc("/user/identities", h.GetUserIdentities).Methods("GET", "OPTIONS")
+	api.HandleFunc("/user/2fa/setup", h.BeginTwoFactorSetup).Methods("POST", "OPTIONS")
+	api.HandleFunc("/user/2fa/enable", h.EnableTwoFactor).Methods("POST", "OPTIONS")
+	api.HandleFunc("/user/2fa/disable", h.DisableTwoFactor).Methods("POST", "OPTIONS")
+	api.HandleFunc("/user/2fa/recovery-codes", h.RegenerateRecoveryCodes).Methods("POST", "OPTIONS")
 
 	// Stadium routes
-	api.HandleFunc("/stadiums", controllers.CreateStadium).Methods("POST", "OPTIONS")
-	api.HandleFunc("/stadiums", controllers.GetStadiums).Methods("GET", "OPTIONS")
-	api.HandleFunc("/stadiums/{id}", controllers.GetStadium).Methods("GET", "OPTIONS")
+	api.Handle("/stadiums", middleware.RequirePermission(authz.StadiumOwn, middleware.RequireVerifiedEmail(h.CreateStadium))).Methods("POST", "OPTIONS")
+	api.Handle("/stadiums", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.GetStadiums))).Methods("GET", "OPTIONS")
+	api.Handle("/stadiums/{id}", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.GetStadium))).Methods("GET", "OPTIONS")
+	api.Handle("/stadiums/{id}", middleware.RequireStadiumPermission(authz.StadiumEdit, "id", h.UpdateStadium)).Methods("PUT", "OPTIONS")
+	api.Handle("/stadiums/{id}", middleware.RequireStadiumPermission(authz.StadiumDelete, "id", h.DeleteStadium)).Methods("DELETE", "OPTIONS")
+	api.Handle("/stadiums/{id}/security", middleware.RequireStadiumPermission(authz.StaffManage, "id", h.UpdateStadiumSecurity)).Methods("PUT", "OPTIONS")
+	api.Handle("/stadiums/{id}/submit", middleware.RequireStadiumPermission(authz.StadiumEdit, "id", h.SubmitStadiumForReview)).Methods("PUT", "OPTIONS")
+	api.Handle("/stadiums/{id}/audit-log", middleware.RequireStadiumPermission(authz.StadiumAudit, "id", h.GetStadiumAuditLog)).Methods("GET", "OPTIONS")
+	api.Handle("/audit-log", middleware.RequirePermission(authz.StadiumOwn, h.GetAuditLog)).Methods("GET", "OPTIONS")
+
+	// Stadium ownership transfer routes
+	api.Handle("/stadiums/{id}/transfers", middleware.RequireStadiumPermission(authz.StadiumTransfer, "id", h.RequestStadiumTransfer)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/stadium-transfers", h.GetStadiumTransfers).Methods("GET", "OPTIONS")
+	api.Handle("/stadium-transfers/{id}/accept", middleware.RequirePermission(authz.StadiumOwn, middleware.RequireVerifiedEmail(h.AcceptStadiumTransfer))).Methods("PUT", "OPTIONS")
+	api.Handle("/stadium-transfers/{id}/decline", middleware.RequirePermission(authz.StadiumOwn, h.DeclineStadiumTransfer)).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/stadium-transfers/{id}/cancel", h.CancelStadiumTransfer).Methods("PUT", "OPTIONS")
+
+	// Stadium staff routes
+	api.Handle("/stadiums/{id}/members", middleware.RequireStadiumPermission(authz.StaffManage, "id", h.InviteStadiumMember)).Methods("POST", "OPTIONS")
+	api.Handle("/stadiums/{id}/members", middleware.RequireStadiumPermission(authz.StadiumView, "id", h.GetStadiumMembers)).Methods("GET", "OPTIONS")
+	api.Handle("/stadiums/{id}/members/{memberId}", middleware.RequireStadiumPermission(authz.StaffManage, "id", h.UpdateStadiumMember)).Methods("PUT", "OPTIONS")
+	api.Handle("/stadiums/{id}/members/{memberId}", middleware.RequireStadiumPermission(authz.StaffManage, "id", h.RemoveStadiumMember)).Methods("DELETE", "OPTIONS")
+	api.Handle("/stadiums/{id}/bookings", middleware.AllowAPIKey(authz.ScopeBookingsRead, middleware.RequireStadiumPermission(authz.BookingView, "id", h.GetStadiumBookings))).Methods("GET", "OPTIONS")
+	api.HandleFunc("/memberships", h.GetMyMemberships).Methods("GET", "OPTIONS")
+	api.HandleFunc("/memberships/{id}/accept", h.AcceptMembership).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/memberships/{id}", h.LeaveMembership).Methods("DELETE", "OPTIONS")
 
 	// Arena routes
-	api.HandleFunc("/arenas", controllers.CreateArena).Methods("POST", "OPTIONS")
-	api.HandleFunc("/arenas", controllers.GetAllArenas).Methods("GET", "OPTIONS")
-	api.HandleFunc("/arenas/search", controllers.SearchArenas).Methods("GET", "OPTIONS")
-	api.HandleFunc("/arenas/{id}", controllers.GetArena).Methods("GET", "OPTIONS")
-	api.HandleFunc("/arenas/{id}", controllers.UpdateArena).Methods("PUT", "OPTIONS")
-	api.HandleFunc("/arenas/{id}", controllers.DeleteArena).Methods("DELETE", "OPTIONS")
-	api.HandleFunc("/stadiums/{stadiumId}/arenas", controllers.GetArenasByStadium).Methods("GET", "OPTIONS")
+	api.HandleFunc("/arenas", h.CreateArena).Methods("POST", "OPTIONS")
+	api.Handle("/arenas", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.GetAllArenas))).Methods("GET", "OPTIONS")
+	api.Handle("/arenas/search", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.SearchArenas))).Methods("GET", "OPTIONS")
+	api.Handle("/arenas/next-available", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.FindAvailableSlots))).Methods("GET", "OPTIONS")
+	api.Handle("/arenas/{id}", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.GetArena))).Methods("GET", "OPTIONS")
+	api.HandleFunc("/arenas/{id}", h.UpdateArena).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/arenas/{id}", h.DeleteArena).Methods("DELETE", "OPTIONS")
+	api.Handle("/stadiums/{stadiumId}/arenas", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.GetArenasByStadium))).Methods("GET", "OPTIONS")
+
+	// Search routes
+	api.Handle("/search", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.Search))).Methods("GET", "OPTIONS")
+	api.Handle("/autocomplete", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.Autocomplete))).Methods("GET", "OPTIONS")
+
+	// Sport type catalog
+	api.Handle("/sport-types", middleware.AllowAPIKey(authz.ScopeAvailabilityRead, http.HandlerFunc(h.GetSportTypes))).Methods("GET", "OPTIONS")
 
 	// Booking routes
-	api.HandleFunc("/bookings", controllers.CreateBooking).Methods("POST", "OPTIONS")
-	api.HandleFunc("/bookings", controllers.GetBookings).Methods("GET", "OPTIONS")
-	api.HandleFunc("/bookings/{id}/cancel", controllers.CancelBooking).Methods("PUT", "DELETE", "OPTIONS")
-	api.HandleFunc("/bookings/{id}/status", controllers.UpdateBookingStatus).Methods("PUT", "OPTIONS")
+	api.Handle("/bookings", middleware.RequirePermission(authz.BookingCreate, middleware.RequireVerifiedEmail(h.CreateBooking))).Methods("POST", "OPTIONS")
+	api.Handle("/bookings", middleware.AllowAPIKey(authz.ScopeBookingsRead, http.HandlerFunc(h.GetBookings))).Methods("GET", "OPTIONS")
+	api.Handle("/bookings/{id}/cancel", middleware.AllowAPIKey(authz.ScopeBookingsWrite, http.HandlerFunc(h.CancelBooking))).Methods("PUT", "DELETE", "OPTIONS")
+	api.Handle("/bookings/{id}/status", middleware.AllowAPIKey(authz.ScopeBookingsWrite, http.HandlerFunc(h.UpdateBookingStatus))).Methods("PUT", "OPTIONS")
+	api.Handle("/bookings/{id}/check-in", middleware.AllowAPIKey(authz.ScopeBookingsWrite, http.HandlerFunc(h.CheckInBooking))).Methods("PUT", "OPTIONS")
+	api.Handle("/bookings/walk-in", middleware.AllowAPIKey(authz.ScopeBookingsWrite, http.HandlerFunc(h.CreateWalkInBooking))).Methods("POST", "OPTIONS")
+
+	// API key routes. Keys can't manage keys; these need a session.
+	api.Handle("/api-keys", middleware.RequirePermission(authz.APIKeyManage, h.CreateAPIKey)).Methods("POST", "OPTIONS")
+	api.Handle("/api-keys", middleware.RequirePermission(authz.APIKeyManage, h.GetAPIKeys)).Methods("GET", "OPTIONS")
+	api.Handle("/api-keys/{id}", middleware.RequirePermission(authz.APIKeyManage, h.RevokeAPIKey)).Methods("DELETE", "OPTIONS")
+
+	// Saved search routes
+	api.HandleFunc("/saved-searches", h.CreateSavedSearch).Methods("POST", "OPTIONS")
+	api.HandleFunc("/saved-searches", h.GetSavedSearches).Methods("GET", "OPTIONS")
+	api.HandleFunc("/saved-searches/{id}", h.DeleteSavedSearch).Methods("DELETE", "OPTIONS")
+
+	// Notification routes
+	api.HandleFunc("/notifications", h.GetNotifications).Methods("GET", "OPTIONS")
+	api.HandleFunc("/notifications/{id}/read", h.MarkNotificationRead).Methods("PUT", "OPTIONS")
+
+	// Admin routes
+	api.Handle("/admin/users", middleware.RequirePermission(authz.UserModerate, h.AdminSearchUsers)).Methods("GET", "OPTIONS")
+	api.Handle("/admin/users/{id}", middleware.RequirePermission(authz.UserModerate, h.AdminGetUser)).Methods("GET", "OPTIONS")
+	api.Handle("/admin/users/{id}/suspend", middleware.RequirePermission(authz.UserModerate, h.AdminSuspendUser)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/users/{id}/reactivate", middleware.RequirePermission(authz.UserModerate, h.AdminReactivateUser)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/users/{id}/role", middleware.RequirePermission(authz.UserModerate, h.AdminSetUserRole)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/lockouts", middleware.RequirePermission(authz.UserModerate, h.AdminGetLockouts)).Methods("GET", "OPTIONS")
+	api.Handle("/admin/lockouts/{id}/clear", middleware.RequirePermission(authz.UserModerate, h.AdminClearLockout)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/stadium-reviews", middleware.RequirePermission(authz.ListingModerate, h.AdminGetStadiumReviews)).Methods("GET", "OPTIONS")
+	api.Handle("/admin/stadiums/{id}/approve", middleware.RequirePermission(authz.ListingModerate, h.AdminApproveStadium)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/stadiums/{id}/reject", middleware.RequirePermission(authz.ListingModerate, h.AdminRejectStadium)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/stadiums/{id}/publish", middleware.RequirePermission(authz.ListingModerate, h.AdminPublishStadium)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/stadiums/{id}/unpublish", middleware.RequirePermission(authz.ListingModerate, h.AdminUnpublishStadium)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/arenas/{id}/publish", middleware.RequirePermission(authz.ListingModerate, h.AdminPublishArena)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/arenas/{id}/unpublish", middleware.RequirePermission(authz.ListingModerate, h.AdminUnpublishArena)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/bookings", middleware.RequirePermission(authz.BookingModerate, h.AdminSearchBookings)).Methods("GET", "OPTIONS")
+	api.Handle("/admin/bookings/{id}", middleware.RequirePermission(authz.BookingModerate, h.AdminGetBooking)).Methods("GET", "OPTIONS")
+	api.Handle("/admin/bookings/{id}/cancel", middleware.RequirePermission(authz.BookingModerate, h.AdminCancelBooking)).Methods("PUT", "OPTIONS")
+	api.Handle("/admin/audit-log", middleware.RequirePermission(authz.AuditView, h.AdminGetAuditLog)).Methods("GET", "OPTIONS")
+
+	// Diagnostics, for admins only
+	debugRoutes := r.PathPrefix("/debug").Subrouter()
+	debugRoutes.Use(middleware.AuthMiddleware(deps))
+	debugRoutes.Handle("/info", middleware.RequirePermission(authz.SystemView, h.GetDebugInfo)).Methods("GET", "OPTIONS")
 
 	// Serve static files (frontend)
 	fileServer := http.FileServer(http.Dir("./frontend/"))
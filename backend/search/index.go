@@ -0,0 +1,344 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Match quality multipliers applied to a term's score depending on how it matched a query token
+const (
+	exactMatchBoost  = 1.0
+	prefixMatchBoost = 0.75
+	typoMatchBoost   = 0.55
+
+	// BM25-style saturation so repeated terms don't dominate the score
+	termSaturation = 1.2
+)
+
+type Document struct {
+	ID      string
+	Fields  map[string]string
+	Payload interface{}
+}
+
+type Result struct {
+	ID      string
+	Score   float64
+	Payload interface{}
+}
+
+type indexedDocument struct {
+	terms   map[string]float64
+	payload interface{}
+}
+
+// Index is an in-memory inverted index with prefix and typo-tolerant matching.
+// It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	weights  map[string]float64
+	docs     map[string]*indexedDocument
+	postings map[string]map[string]float64
+
+	// Sorted vocabulary for prefix lookups, rebuilt lazily after writes
+	vocabulary      []string
+	vocabularyDirty bool
+}
+
+func NewIndex(fieldWeights map[string]float64) *Index {
+	return &Index{
+		weights:  fieldWeights,
+		docs:     make(map[string]*indexedDocument),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// Add indexes a document, replacing any existing document with the same ID
+func (idx *Index) Add(doc Document) {
+	terms := make(map[string]float64)
+	for field, text := range doc.Fields {
+		weight, ok := idx.weights[field]
+		if !ok {
+			weight = 1
+		}
+		for _, token := range Tokenize(text) {
+			terms[token] += weight
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(doc.ID)
+
+	idx.docs[doc.ID] = &indexedDocument{terms: terms, payload: doc.Payload}
+	for term, weight := range terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[string]float64)
+			idx.postings[term] = posting
+			idx.vocabularyDirty = true
+		}
+		posting[doc.ID] = weight
+	}
+}
+
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(id)
+}
+
+func (idx *Index) removeLocked(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range doc.terms {
+		posting := idx.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+			idx.vocabularyDirty = true
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Replace swaps the whole index contents in one step, e.g. after a full rebuild
+func (idx *Index) Replace(docs []Document) {
+	fresh := NewIndex(idx.weights)
+	for _, doc := range docs {
+		fresh.Add(doc)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs = fresh.docs
+	idx.postings = fresh.postings
+	idx.vocabularyDirty = true
+}
+
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search ranks documents against the query. Each query token matches terms exactly,
+// by prefix, or within a small edit distance, and contributes its best match per document.
+// Documents matching more of the query tokens rank higher.
+func (idx *Index) Search(query string, limit int) []Result {
+	tokens := uniqueTokens(Tokenize(query))
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	idx.ensureVocabulary()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	totalDocs := float64(len(idx.docs))
+	scores := make(map[string]float64)
+	matchedTokens := make(map[string]int)
+
+	for _, token := range tokens {
+		best := make(map[string]float64)
+		for term, quality := range idx.expandToken(token) {
+			posting := idx.postings[term]
+			docFreq := float64(len(posting))
+			idf := math.Log(1 + (totalDocs-docFreq+0.5)/(docFreq+0.5))
+			for docID, weight := range posting {
+				tf := weight * (termSaturation + 1) / (weight + termSaturation)
+				score := quality * idf * tf
+				if score > best[docID] {
+					best[docID] = score
+				}
+			}
+		}
+		for docID, score := range best {
+			scores[docID] += score
+			matchedTokens[docID]++
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for docID, score := range scores {
+		coverage := float64(matchedTokens[docID]) / float64(len(tokens))
+		results = append(results, Result{
+			ID:      docID,
+			Score:   score * coverage * coverage,
+			Payload: idx.docs[docID].payload,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// expandToken returns every indexed term the token can match, with its match quality
+func (idx *Index) expandToken(token string) map[string]float64 {
+	matches := make(map[string]float64)
+
+	if _, ok := idx.postings[token]; ok {
+		matches[token] = exactMatchBoost
+	}
+
+	// Prefix matches, so "foot" finds "football" while the user is still typing
+	if len([]rune(token)) >= 2 {
+		start := sort.SearchStrings(idx.vocabulary, token)
+		for i := start; i < len(idx.vocabulary) && strings.HasPrefix(idx.vocabulary[i], token); i++ {
+			term := idx.vocabulary[i]
+			if term == token {
+				continue
+			}
+			// Shorter completions are closer to what the user meant
+			quality := prefixMatchBoost * float64(len(token)) / float64(len(term))
+			matches[term] = math.Max(quality, prefixMatchBoost/2)
+		}
+	}
+
+	// Typo tolerance scales with token length to keep short words precise
+	maxEdits := allowedEdits(token)
+	if maxEdits == 0 {
+		return matches
+	}
+
+	tokenRunes := []rune(token)
+	for _, term := range idx.vocabulary {
+		if _, ok := matches[term]; ok {
+			continue
+		}
+		termRunes := []rune(term)
+		if abs(len(termRunes)-len(tokenRunes)) > maxEdits {
+			continue
+		}
+		distance := editDistance(tokenRunes, termRunes, maxEdits)
+		if distance <= maxEdits {
+			matches[term] = typoMatchBoost / float64(distance)
+		}
+	}
+
+	return matches
+}
+
+func (idx *Index) ensureVocabulary() {
+	idx.mu.RLock()
+	dirty := idx.vocabularyDirty
+	idx.mu.RUnlock()
+	if !dirty {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.vocabularyDirty {
+		return
+	}
+
+	vocabulary := make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		vocabulary = append(vocabulary, term)
+	}
+	sort.Strings(vocabulary)
+	idx.vocabulary = vocabulary
+	idx.vocabularyDirty = false
+}
+
+// Tokenize lower-cases text and splits it on anything that isn't a letter or digit
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	var unique []string
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
+
+func allowedEdits(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance (Levenshtein plus adjacent
+// transpositions). It stops early and returns maxEdits+1 once the bound is exceeded.
+func editDistance(a, b []rune, maxEdits int) int {
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prevPrev[j-2]+1 < curr[j] {
+				curr[j] = prevPrev[j-2] + 1
+			}
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > maxEdits {
+			return maxEdits + 1
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
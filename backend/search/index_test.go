@@ -0,0 +1,168 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestIndex() *Index {
+	idx := NewIndex(map[string]float64{"name": 3, "location": 1})
+	for _, doc := range []Document{
+		{ID: "1", Fields: map[string]string{"name": "Gulberg Football Arena", "location": "Lahore"}},
+		{ID: "2", Fields: map[string]string{"name": "Model Town Cricket Ground", "location": "Lahore"}},
+		{ID: "3", Fields: map[string]string{"name": "Karachi Futsal Club", "location": "Karachi"}},
+		{ID: "4", Fields: map[string]string{"name": "DHA Football Turf", "location": "Karachi"}},
+	} {
+		idx.Add(doc)
+	}
+	return idx
+}
+
+func resultIDs(results []Result) []string {
+	ids := []string{}
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	idx := newTestIndex()
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"exact term, ties broken by ID", "football", 0, []string{"1", "4"}},
+		{"case and punctuation ignored", "FOOTBALL!", 0, []string{"1", "4"}},
+		{"covering every token ranks first", "football karachi", 0, []string{"4", "3", "1"}},
+		{"prefix", "foot", 0, []string{"1", "4"}},
+		{"prefix of a location", "lahor", 0, []string{"1", "2"}},
+		{"one typo", "fotball", 0, []string{"1", "4"}},
+		{"one typo in a shorter word", "criket", 0, []string{"2"}},
+		{"short tokens must match exactly", "dha", 0, []string{"4"}},
+		{"short tokens get no typo tolerance", "dhb", 0, []string{}},
+		{"no match", "tennis", 0, []string{}},
+		{"limit", "lahore karachi", 2, []string{"3", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resultIDs(idx.Search(tt.query, tt.limit))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchEmptyQuery(t *testing.T) {
+	idx := newTestIndex()
+	for _, query := range []string{"", "   ", "-!?"} {
+		if results := idx.Search(query, 0); results != nil {
+			t.Errorf("Search(%q) = %v, want nil", query, results)
+		}
+	}
+}
+
+func TestSearchPrefersCloserMatches(t *testing.T) {
+	idx := NewIndex(nil)
+	idx.Add(Document{ID: "exact", Fields: map[string]string{"name": "Padel"}})
+	idx.Add(Document{ID: "prefix", Fields: map[string]string{"name": "Padels"}})
+	idx.Add(Document{ID: "typo", Fields: map[string]string{"name": "Padle"}})
+
+	results := idx.Search("padel", 0)
+	if got, want := resultIDs(results), []string{"exact", "prefix", "typo"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Search(padel) = %v, want %v", got, want)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score >= results[i-1].Score {
+			t.Errorf("%s scored %v, not below %s's %v", results[i].ID, results[i].Score, results[i-1].ID, results[i-1].Score)
+		}
+	}
+}
+
+func TestSearchFieldWeights(t *testing.T) {
+	idx := NewIndex(map[string]float64{"name": 3, "location": 1})
+	idx.Add(Document{ID: "in-location", Fields: map[string]string{"name": "Central Arena", "location": "Johar Town"}})
+	idx.Add(Document{ID: "in-name", Fields: map[string]string{"name": "Johar Arena", "location": "Lahore"}})
+
+	if got, want := resultIDs(idx.Search("johar", 0)), []string{"in-name", "in-location"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(johar) = %v, want %v", got, want)
+	}
+}
+
+func TestIndexUpdates(t *testing.T) {
+	idx := newTestIndex()
+
+	idx.Remove("1")
+	if got, want := resultIDs(idx.Search("football", 0)), []string{"4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Remove, Search(football) = %v, want %v", got, want)
+	}
+	if got := resultIDs(idx.Search("gulberg", 0)); len(got) != 0 {
+		t.Errorf("after Remove, Search(gulberg) = %v, want no results", got)
+	}
+
+	// Adding an existing ID replaces the document
+	idx.Add(Document{ID: "4", Fields: map[string]string{"name": "DHA Padel Turf"}, Payload: 4})
+	if got := resultIDs(idx.Search("football", 0)); len(got) != 0 {
+		t.Errorf("after re-adding, Search(football) = %v, want no results", got)
+	}
+	if results := idx.Search("padel", 0); len(results) != 1 || results[0].Payload != 4 {
+		t.Errorf("after re-adding, Search(padel) = %+v, want document 4 with its payload", results)
+	}
+
+	idx.Replace([]Document{{ID: "9", Fields: map[string]string{"name": "Fresh Start"}}})
+	if idx.Len() != 1 {
+		t.Errorf("after Replace, Len() = %d, want 1", idx.Len())
+	}
+	if got, want := resultIDs(idx.Search("fresh", 0)), []string{"9"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Replace, Search(fresh) = %v, want %v", got, want)
+	}
+	if got := resultIDs(idx.Search("padel", 0)); len(got) != 0 {
+		t.Errorf("after Replace, Search(padel) = %v, want no results", got)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Gulberg, Lahore", []string{"gulberg", "lahore"}},
+		{"5-a-side Futsal", []string{"5", "a", "side", "futsal"}},
+		{"Ëlite  Spörts", []string{"ëlite", "spörts"}},
+		{" ,.- ", []string{}},
+	}
+	for _, tt := range tests {
+		got := Tokenize(tt.text)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		maxEdits int
+		want     int
+	}{
+		{"arena", "arena", 2, 0},
+		{"arena", "arenas", 2, 1},
+		{"cricket", "criket", 2, 1},
+		{"football", "footabll", 2, 1}, // adjacent transposition counts once
+		{"kitten", "sitting", 3, 3},
+		{"", "abc", 3, 3},
+		{"abcdef", "uvwxyz", 2, 3}, // gives up past the bound
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b), tt.maxEdits); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.maxEdits, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A suggestion scores higher when the query matches the start of the phrase rather than a later word
+const phraseStartBoost = 2.0
+
+type Suggestion struct {
+	Text     string
+	Keywords []string
+	Weight   float64
+	Payload  interface{}
+}
+
+type SuggestResult struct {
+	Text    string
+	Score   float64
+	Payload interface{}
+}
+
+type suggestKey struct {
+	key         string
+	suggestion  int
+	phraseStart bool
+}
+
+// Suggester answers prefix queries from a sorted key list, so each lookup is a binary search
+// plus a scan over the matching range. It is safe for concurrent use.
+type Suggester struct {
+	mu          sync.RWMutex
+	suggestions []Suggestion
+	keys        []suggestKey
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{}
+}
+
+// Replace swaps in a new set of suggestions. Every word of the text and keywords becomes
+// a key, so "Lahore" is found by typing "lah" in "Gulberg, Lahore".
+func (s *Suggester) Replace(suggestions []Suggestion) {
+	var keys []suggestKey
+	for i, suggestion := range suggestions {
+		phrases := append([]string{suggestion.Text}, suggestion.Keywords...)
+		for _, phrase := range phrases {
+			tokens := Tokenize(phrase)
+			for start := range tokens {
+				keys = append(keys, suggestKey{
+					key:         strings.Join(tokens[start:], " "),
+					suggestion:  i,
+					phraseStart: start == 0,
+				})
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].key < keys[j].key
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suggestions = suggestions
+	s.keys = keys
+}
+
+func (s *Suggester) Suggest(prefix string, limit int, accept func(payload interface{}) bool) []SuggestResult {
+	normalized := strings.Join(Tokenize(prefix), " ")
+	if normalized == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := make(map[int]float64)
+	start := sort.Search(len(s.keys), func(i int) bool {
+		return s.keys[i].key >= normalized
+	})
+	for i := start; i < len(s.keys) && strings.HasPrefix(s.keys[i].key, normalized); i++ {
+		key := s.keys[i]
+		suggestion := s.suggestions[key.suggestion]
+		if accept != nil && !accept(suggestion.Payload) {
+			continue
+		}
+
+		score := suggestion.Weight
+		if key.phraseStart {
+			score *= phraseStartBoost
+		}
+		// Prefer completions that are closer in length to what was typed
+		score *= float64(len(normalized)+1) / float64(len(key.key)+1)
+
+		if score > scores[key.suggestion] {
+			scores[key.suggestion] = score
+		}
+	}
+
+	results := make([]SuggestResult, 0, len(scores))
+	for i, score := range scores {
+		results = append(results, SuggestResult{
+			Text:    s.suggestions[i].Text,
+			Score:   score,
+			Payload: s.suggestions[i].Payload,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Text < results[j].Text
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
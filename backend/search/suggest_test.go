@@ -0,0 +1,76 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestSuggester() *Suggester {
+	s := NewSuggester()
+	s.Replace([]Suggestion{
+		{Text: "Gulberg, Lahore", Weight: 1, Payload: "area"},
+		{Text: "Lahore", Weight: 1, Payload: "city"},
+		{Text: "Lahore Cantt", Weight: 1, Payload: "area"},
+		{Text: "Karachi", Keywords: []string{"Clifton", "Saddar"}, Weight: 1, Payload: "city"},
+		{Text: "Kasur", Weight: 3, Payload: "city"},
+	})
+	return s
+}
+
+func suggestionTexts(results []SuggestResult) []string {
+	texts := []string{}
+	for _, result := range results {
+		texts = append(texts, result.Text)
+	}
+	return texts
+}
+
+func TestSuggest(t *testing.T) {
+	s := newTestSuggester()
+	cityOnly := func(payload interface{}) bool { return payload == "city" }
+
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		accept func(payload interface{}) bool
+		want   []string
+	}{
+		{"phrase start beats a later word", "lah", 0, nil, []string{"Lahore", "Lahore Cantt", "Gulberg, Lahore"}},
+		{"later word of the text", "gulb", 0, nil, []string{"Gulberg, Lahore"}},
+		{"multi-word prefix", "lahore ca", 0, nil, []string{"Lahore Cantt"}},
+		{"keywords", "clif", 0, nil, []string{"Karachi"}},
+		{"weight", "ka", 0, nil, []string{"Kasur", "Karachi"}},
+		{"normalized like the index", "  LAHORE,", 0, nil, []string{"Lahore", "Lahore Cantt", "Gulberg, Lahore"}},
+		{"accept filter", "lah", 0, cityOnly, []string{"Lahore"}},
+		{"limit", "lah", 2, nil, []string{"Lahore", "Lahore Cantt"}},
+		{"no match", "quetta", 0, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestionTexts(s.Suggest(tt.prefix, tt.limit, tt.accept))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestEmptyPrefix(t *testing.T) {
+	s := newTestSuggester()
+	if results := s.Suggest(" - ", 0, nil); results != nil {
+		t.Errorf("Suggest of an empty prefix = %v, want nil", results)
+	}
+}
+
+func TestSuggesterReplace(t *testing.T) {
+	s := newTestSuggester()
+	s.Replace([]Suggestion{{Text: "Islamabad", Weight: 1}})
+
+	if got := suggestionTexts(s.Suggest("lah", 0, nil)); len(got) != 0 {
+		t.Errorf("after Replace, Suggest(lah) = %q, want no results", got)
+	}
+	if got, want := suggestionTexts(s.Suggest("isl", 0, nil)), []string{"Islamabad"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Replace, Suggest(isl) = %q, want %q", got, want)
+	}
+}
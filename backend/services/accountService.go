@@ -0,0 +1,433 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/mail"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	UserStatusDeleted = "Deleted"
+
+	maxFullNameLength = 100
+	maxEmailLength    = 255
+
+	emailChangeTTL = 24 * time.Hour
+
+	deletedUserName = "Deleted user"
+)
+
+// UpdateProfile renames the user straight away. A new email is only recorded as pending: the user
+// keeps signing in with the old one until the link mailed to the new address is opened.
+func UpdateProfile(deps *Deps, userID int, req models.UpdateProfileRequest) (*models.ProfileUpdateResult, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := strings.TrimSpace(req.FullName)
+	if fullName == "" {
+		return nil, errors.New("full name is required")
+	}
+	if len(fullName) > maxFullNameLength {
+		return nil, fmt.Errorf("full name must be at most %d characters", maxFullNameLength)
+	}
+
+	email := strings.TrimSpace(req.Email)
+	emailChanged := email != "" && !strings.EqualFold(email, user.Email)
+	if emailChanged {
+		if len(email) < 5 || len(email) > maxEmailLength || !strings.Contains(email, "@") {
+			return nil, errors.New("invalid email format")
+		}
+		// Accounts from an external provider have no password; the session is all they can offer
+		if user.PasswordHash != "" && !VerifyPassword(user.PasswordHash, req.Password) {
+			return nil, errors.New("password is incorrect")
+		}
+		if err := checkEmailAvailable(email, userID); err != nil {
+			return nil, err
+		}
+
+		sent, err := recentUserTokenCount(userID, TokenPurposeEmailChange, verificationEmailWindow)
+		if err != nil {
+			return nil, err
+		}
+		if sent >= maxVerificationEmails {
+			return nil, errors.New("too many email change requests, please try again later")
+		}
+	}
+
+	if fullName != user.FullName {
+		_, err := config.DB.Exec("UPDATE Users SET FullName = @p1 WHERE UserId = @p2", fullName, userID)
+		if err != nil {
+			return nil, err
+		}
+		invalidateCachedUser(userID)
+		user.FullName = fullName
+	}
+
+	result := &models.ProfileUpdateResult{User: user}
+	if !emailChanged {
+		return result, nil
+	}
+
+	_, err = config.DB.Exec("UPDATE Users SET PendingEmail = @p1 WHERE UserId = @p2", email, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sendEmailChangeConfirmation(deps, user, email); err != nil {
+		return nil, err
+	}
+	notifyEmailChangeRequested(deps, user, email)
+
+	result.PendingEmail = email
+	return result, nil
+}
+
+// ConfirmEmailChange redeems the link sent to a pending address and makes it the account's email.
+// It returns the updated user and the address it replaced.
+func ConfirmEmailChange(token string) (*models.User, string, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, "", errors.New("token is required")
+	}
+
+	userID, err := consumeUserToken(token, TokenPurposeEmailChange)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var oldEmail string
+	var pendingEmail sql.NullString
+	err = config.DB.QueryRow("SELECT Email, PendingEmail FROM Users WHERE UserId = @p1", userID).Scan(&oldEmail, &pendingEmail)
+	if err != nil {
+		return nil, "", err
+	}
+	if !pendingEmail.Valid {
+		return nil, "", errors.New("this link is invalid or has expired")
+	}
+
+	// Someone may have signed up with the address since the change was requested
+	if err := checkEmailAvailable(pendingEmail.String, userID); err != nil {
+		return nil, "", err
+	}
+
+	result, err := config.DB.Exec(`
+		UPDATE Users SET Email = PendingEmail, PendingEmail = NULL, EmailVerified = 1, EmailVerifiedAt = @p1
+		WHERE UserId = @p2 AND PendingEmail = @p3
+	`, time.Now(), userID, pendingEmail.String)
+	if err != nil {
+		return nil, "", err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, "", errors.New("this link is invalid or has expired")
+	}
+	invalidateCachedUser(userID)
+
+	// Reset links went to the old address, which no longer controls the account
+	_, err = config.DB.Exec(
+		"UPDATE UserTokens SET ExpiresAt = @p1 WHERE UserId = @p2 AND Purpose = @p3 AND UsedAt IS NULL AND ExpiresAt > @p1",
+		time.Now(), userID, TokenPurposePasswordReset,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, oldEmail, nil
+}
+
+// DeleteAccount closes the account for good. Booking history stays for the stadiums' records, but
+// the user row is stripped of the name, email and password, upcoming bookings are cancelled and
+// everything else tied to the person (sessions, sign-in methods, saved searches, notifications,
+// API keys, staff memberships) is removed. It returns how many bookings were cancelled.
+func DeleteAccount(deps *Deps, userID int, req models.DeleteAccountRequest) (int, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.Status == UserStatusDeleted {
+		return 0, errors.New("account has already been deleted")
+	}
+
+	if user.PasswordHash != "" && !VerifyPassword(user.PasswordHash, req.Password) {
+		return 0, errors.New("password is incorrect")
+	}
+	if user.TwoFactorEnabled {
+		if _, err := verifySecondFactor(userID, req.Code); err != nil {
+			return 0, err
+		}
+	}
+
+	var ownedStadiums int
+	err = config.DB.QueryRow("SELECT COUNT(*) FROM Stadiums WHERE OwnerId = @p1", userID).Scan(&ownedStadiums)
+	if err != nil {
+		return 0, err
+	}
+	if ownedStadiums > 0 {
+		return 0, errors.New("transfer or delete your stadiums before deleting your account")
+	}
+
+	now := time.Now()
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Walk-ins entered by staff belong to their guests, so only the user's own bookings are cancelled
+	arenaIDs, err := queryInts(tx, `
+		UPDATE Bookings SET Status = 'Cancelled', CancellationReason = @p1
+		OUTPUT INSERTED.ArenaId
+		WHERE UserId = @p2 AND WalkInName = '' AND Status <> 'Cancelled' AND SlotStart > @p3
+	`, "Account deleted", userID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE Users
+		SET FullName = @p1, Email = @p2, PendingEmail = NULL, PasswordHash = '', Status = @p3,
+		    EmailVerified = 0, EmailVerifiedAt = NULL, TwoFactorEnabled = 0, DeletedAt = @p4
+		WHERE UserId = @p5
+	`, deletedUserName, deletedUserEmail(userID), UserStatusDeleted, now, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, table := range []string{"UserTokens", "UserTwoFactor", "UserRecoveryCodes", "UserIdentities", "SavedSearches", "Notifications", "ApiKeys", "StadiumMembers"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE UserId = @p1", userID); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE StadiumTransfers SET Status = 'Cancelled', RespondedAt = @p1 WHERE Status = 'Pending' AND (FromOwnerId = @p2 OR ToOwnerId = @p2)",
+		now, userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// Login throttling is keyed by email; drop the live counter and anonymize the lockout history
+	_, err = tx.Exec("DELETE FROM LoginThrottles WHERE Scope = @p1 AND ThrottleKey = @p2", LoginThrottleAccount, loginThrottleKey(user.Email))
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(
+		"UPDATE AccountLockouts SET ThrottleKey = @p1 WHERE UserId = @p2 AND Scope = @p3",
+		deletedUserEmail(userID), userID, LoginThrottleAccount,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	tokens, err := queryStrings(tx, "DELETE FROM Sessions OUTPUT DELETED.Token WHERE UserId = @p1", userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	invalidateCachedSessions(tokens...)
+	invalidateCachedUser(userID)
+
+	notified := make(map[int]bool)
+	for _, arenaID := range arenaIDs {
+		if !notified[arenaID] {
+			notified[arenaID] = true
+			notifySavedSearchesAsync(deps, arenaID)
+		}
+	}
+
+	notifyAccountDeleted(user)
+	return len(arenaIDs), nil
+}
+
+// ExportAccountData gathers everything held about the user for a personal data request
+func ExportAccountData(userID int) (*models.AccountExport, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = ""
+
+	export := &models.AccountExport{
+		ExportedAt: time.Now(),
+		User:       models.AccountExportUser{User: *user},
+	}
+
+	var pendingEmail sql.NullString
+	var emailVerifiedAt sql.NullTime
+	err = config.DB.QueryRow("SELECT PendingEmail, EmailVerifiedAt FROM Users WHERE UserId = @p1", userID).Scan(&pendingEmail, &emailVerifiedAt)
+	if err != nil {
+		return nil, err
+	}
+	export.User.PendingEmail = pendingEmail.String
+	if emailVerifiedAt.Valid {
+		export.User.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+
+	if export.Sessions, err = GetUserSessions(userID, ""); err != nil {
+		return nil, err
+	}
+	if export.Bookings, err = GetBookingsByUser(userID); err != nil {
+		return nil, err
+	}
+	if export.Identities, err = GetUserIdentities(userID); err != nil {
+		return nil, err
+	}
+	if export.Memberships, err = GetMembershipsByUser(userID); err != nil {
+		return nil, err
+	}
+	if export.SavedSearches, err = GetSavedSearchesByUser(userID); err != nil {
+		return nil, err
+	}
+	if export.Notifications, err = GetNotificationsByUser(userID, false); err != nil {
+		return nil, err
+	}
+	if export.APIKeys, err = GetAPIKeys(userID); err != nil {
+		return nil, err
+	}
+	if export.ListingModeration, err = GetStadiumReviewsByOwner(userID); err != nil {
+		return nil, err
+	}
+
+	// Empty lists rather than nulls, so the archive reads the same whatever the account has
+	if export.Sessions == nil {
+		export.Sessions = []models.Session{}
+	}
+	if export.Bookings == nil {
+		export.Bookings = []models.BookingWithDetails{}
+	}
+	if export.Identities == nil {
+		export.Identities = []models.UserIdentity{}
+	}
+	if export.Memberships == nil {
+		export.Memberships = []models.StadiumMemberWithDetails{}
+	}
+	if export.SavedSearches == nil {
+		export.SavedSearches = []models.SavedSearch{}
+	}
+	if export.Notifications == nil {
+		export.Notifications = []models.Notification{}
+	}
+	if export.APIKeys == nil {
+		export.APIKeys = []models.APIKey{}
+	}
+	if export.ListingModeration == nil {
+		export.ListingModeration = []models.StadiumReview{}
+	}
+
+	return export, nil
+}
+
+// checkEmailAvailable fails if another account already uses the address
+func checkEmailAvailable(email string, userID int) error {
+	var count int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM Users WHERE Email = @p1 AND UserId <> @p2", email, userID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("email already exists")
+	}
+	return nil
+}
+
+// deletedUserEmail keeps Users.Email unique for deleted accounts without holding a real address
+func deletedUserEmail(userID int) string {
+	return fmt.Sprintf("deleted-%d@deleted.invalid", userID)
+}
+
+func sendEmailChangeConfirmation(deps *Deps, user *models.User, newEmail string) error {
+	token, err := issueUserToken(user.UserID, TokenPurposeEmailChange, emailChangeTTL)
+	if err != nil {
+		return err
+	}
+
+	link := deps.appURL("/frontend/pages/verify-email.html?change=1&token=" + url.QueryEscape(token))
+	return mail.Send(mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new BookMyArena email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nTo start using this address for your BookMyArena account, open this link:\n\n%s\n\nThe link expires in %d hours. Until then you keep signing in with your current address. If you didn't ask for this, you can ignore this email.\n",
+			user.FullName, link, int(emailChangeTTL.Hours()),
+		),
+	})
+}
+
+// notifyEmailChangeRequested warns the current address, so a change made from a stolen session doesn't go unnoticed
+func notifyEmailChangeRequested(deps *Deps, user *models.User, newEmail string) {
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your BookMyArena email address is being changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to change the email address of your BookMyArena account to %s. The change only happens once that address is confirmed.\n\nIf this wasn't you, change your password right away at %s\n",
+			user.FullName, newEmail, deps.appURL("/frontend/pages/forgot-password.html"),
+		),
+	})
+	if err != nil {
+		log.Println("Error sending email change notice:", err)
+	}
+}
+
+func notifyAccountDeleted(user *models.User) {
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your BookMyArena account was deleted",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour BookMyArena account has been deleted and your upcoming bookings were cancelled. Past bookings are kept for the stadiums' records without your name or email.\n",
+			user.FullName,
+		),
+	})
+	if err != nil {
+		log.Println("Error sending account deletion email:", err)
+	}
+}
+
+func queryInts(tx *sql.Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []int
+	for rows.Next() {
+		var value int
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func queryStrings(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
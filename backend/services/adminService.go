@@ -0,0 +1,296 @@
+package services
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	NotificationTypeListingUnpublished = "ListingUnpublished"
+	NotificationTypeListingPublished   = "ListingPublished"
+	NotificationTypeBookingCancelled   = "BookingCancelled"
+
+	defaultAdminBookingLimit = 100
+	maxAdminBookingLimit     = 500
+)
+
+func SearchUsers(params models.UserSearchParams) (*models.PaginatedUsers, error) {
+	if params.PageNumber < 1 {
+		params.PageNumber = 1
+	}
+	if params.PageSize < 1 {
+		params.PageSize = 20
+	}
+	if params.PageSize > 100 {
+		params.PageSize = 100
+	}
+	params.Query = strings.TrimSpace(params.Query)
+
+	where := `
+		WHERE (@p1 = '' OR FullName LIKE '%' + @p1 + '%' OR Email LIKE '%' + @p1 + '%')
+		  AND (@p2 = '' OR Role = @p2)
+		  AND (@p3 = '' OR Status = @p3)
+	`
+
+	var totalCount int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM Users"+where, params.Query, params.Role, params.Status).Scan(&totalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (params.PageNumber - 1) * params.PageSize
+	rows, err := config.DB.Query(
+		"SELECT "+userColumns+" FROM Users"+where+"ORDER BY CreatedAt DESC OFFSET @p4 ROWS FETCH NEXT @p5 ROWS ONLY",
+		params.Query, params.Role, params.Status, offset, params.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	return &models.PaginatedUsers{
+		Users:      users,
+		TotalCount: totalCount,
+		PageNumber: params.PageNumber,
+		PageSize:   params.PageSize,
+		TotalPages: (totalCount + params.PageSize - 1) / params.PageSize,
+	}, nil
+}
+
+// SuspendUser blocks an account from logging in and ends all of its sessions
+func SuspendUser(adminID, userID int, reason string) error {
+	if adminID == userID {
+		return errors.New("you cannot suspend your own account")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Status == UserStatusSuspended {
+		return errors.New("account is already suspended")
+	}
+	if user.Status == UserStatusDeleted {
+		return errors.New("account has been deleted")
+	}
+
+	_, err = config.DB.Exec("UPDATE Users SET Status = @p1 WHERE UserId = @p2", UserStatusSuspended, userID)
+	if err != nil {
+		return err
+	}
+	invalidateCachedUser(userID)
+
+	if err := DeleteUserSessions(userID, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ReactivateUser(adminID, userID int) error {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Status != UserStatusSuspended {
+		return errors.New("account is not suspended")
+	}
+
+	_, err = config.DB.Exec("UPDATE Users SET Status = @p1 WHERE UserId = @p2", UserStatusActive, userID)
+	if err != nil {
+		return err
+	}
+	invalidateCachedUser(userID)
+
+	return nil
+}
+
+func SetUserRole(adminID, userID int, role string) error {
+	if !authz.IsRole(role) {
+		return errors.New("invalid role")
+	}
+	if adminID == userID {
+		return errors.New("you cannot change your own role")
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Status == UserStatusDeleted {
+		return errors.New("account has been deleted")
+	}
+	if user.Role == role {
+		return nil
+	}
+
+	_, err = config.DB.Exec("UPDATE Users SET Role = @p1 WHERE UserId = @p2", role, userID)
+	if err != nil {
+		return err
+	}
+	invalidateCachedUser(userID)
+
+	return nil
+}
+
+// SetStadiumPublished hides a stadium and all of its arenas from listings, search and booking, or restores them
+func SetStadiumPublished(adminID, stadiumID int, published bool, reason string) error {
+	if !published && strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+
+	stadium, err := GetStadiumByID(stadiumID)
+	if err != nil {
+		return err
+	}
+	if stadium.IsPublished == published {
+		return nil
+	}
+
+	_, err = config.DB.Exec("UPDATE Stadiums SET IsPublished = @p1 WHERE StadiumId = @p2", published, stadiumID)
+	if err != nil {
+		return err
+	}
+
+	syncSearchIndexForStadium(stadiumID)
+	invalidateAutocomplete()
+
+	notifyListingModerated(stadium.OwnerID, stadium.Name, published, reason, map[string]int{"stadiumId": stadiumID})
+
+	return nil
+}
+
+func SetArenaPublished(adminID, arenaID int, published bool, reason string) error {
+	if !published && strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+
+	arena, err := GetArenaByID(arenaID)
+	if err != nil {
+		return err
+	}
+	if arena.IsPublished == published {
+		return nil
+	}
+
+	stadium, err := GetStadiumByID(arena.StadiumID)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.DB.Exec("UPDATE Arenas SET IsPublished = @p1 WHERE ArenaId = @p2", published, arenaID)
+	if err != nil {
+		return err
+	}
+
+	syncSearchIndexForStadium(arena.StadiumID)
+	invalidateAutocomplete()
+
+	notifyListingModerated(stadium.OwnerID, arena.Name, published, reason, map[string]int{
+		"stadiumId": arena.StadiumID,
+		"arenaId":   arenaID,
+	})
+
+	return nil
+}
+
+// SearchBookings lists bookings across all stadiums for admins
+func SearchBookings(params models.BookingSearchParams) ([]models.BookingWithDetails, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultAdminBookingLimit
+	}
+	if params.Limit > maxAdminBookingLimit {
+		params.Limit = maxAdminBookingLimit
+	}
+
+	query := `
+		SELECT TOP (@p6) b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.WalkInName, b.CheckedInAt, b.CancellationReason, b.CreatedAt,
+		       a.Name AS ArenaName, s.Name AS StadiumName, s.Location, a.SportType, a.Price
+		FROM Bookings b
+		INNER JOIN Arenas a ON b.ArenaId = a.ArenaId
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE (@p1 = 0 OR b.BookingId = @p1)
+		  AND (@p2 = 0 OR b.UserId = @p2)
+		  AND (@p3 = 0 OR s.StadiumId = @p3)
+		  AND (@p4 = 0 OR b.ArenaId = @p4)
+		  AND (@p5 = '' OR b.Status = @p5)
+		ORDER BY b.SlotStart DESC
+	`
+
+	rows, err := config.DB.Query(query, params.BookingID, params.UserID, params.StadiumID, params.ArenaID, params.Status, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBookingsWithDetails(rows)
+}
+
+// ForceCancelBooking cancels any booking regardless of who made it or when it starts
+func ForceCancelBooking(deps *Deps, adminID, bookingID int, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required")
+	}
+
+	booking, err := GetBookingByID(bookingID)
+	if err != nil {
+		return err
+	}
+	if booking.Status == "Cancelled" {
+		return errors.New("booking is already cancelled")
+	}
+
+	_, err = config.DB.Exec(
+		"UPDATE Bookings SET Status = 'Cancelled', CancellationReason = @p1 WHERE BookingId = @p2",
+		reason, bookingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Walk-in bookings are held by the staff member who made them, so there is no customer account to notify
+	if booking.WalkInName == "" {
+		message := fmt.Sprintf("Your booking on %s was cancelled by BookMyArena: %s", booking.SlotStart.Format("Mon 2 Jan 15:04"), reason)
+		if err := CreateNotification(booking.UserID, NotificationTypeBookingCancelled, message, map[string]int{"bookingId": bookingID}); err != nil {
+			log.Println("Error creating cancellation notification:", err)
+		}
+	}
+
+	if booking.SlotStart.After(time.Now()) {
+		notifySavedSearchesAsync(deps, booking.ArenaID)
+	}
+
+	return nil
+}
+
+func notifyListingModerated(ownerID int, name string, published bool, reason string, data interface{}) {
+	notificationType := NotificationTypeListingPublished
+	message := fmt.Sprintf("%s is visible to customers again", name)
+	if !published {
+		notificationType = NotificationTypeListingUnpublished
+		message = fmt.Sprintf("%s was unpublished by BookMyArena: %s", name, reason)
+	}
+
+	if err := CreateNotification(ownerID, notificationType, message, data); err != nil {
+		log.Println("Error creating moderation notification:", err)
+	}
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every key, so a key is easy to spot in a config file or a leaked log
+	APIKeyPrefix = "bma_"
+
+	// How much of the key is stored in the clear to tell keys apart
+	apiKeyDisplayLength = 12
+
+	maxActiveAPIKeys = 25
+)
+
+const apiKeyColumns = "KeyId, UserId, Name, KeyPrefix, Scopes, ExpiresAt, LastUsedAt, LastUsedIp, RevokedAt, CreatedAt"
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a session token
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// CreateAPIKey issues a key for the owner. The key itself is only in the return value; the database
+// keeps its hash.
+func CreateAPIKey(userID int, req models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, errors.New("name is required and must be at most 100 characters")
+	}
+
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiresAt must be in the future")
+	}
+
+	var active int
+	err = config.DB.QueryRow(
+		"SELECT COUNT(*) FROM ApiKeys WHERE UserId = @p1 AND RevokedAt IS NULL AND (ExpiresAt IS NULL OR ExpiresAt > @p2)",
+		userID, time.Now(),
+	).Scan(&active)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxActiveAPIKeys {
+		return nil, errors.New("too many active API keys, revoke one first")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	apiKey, err := scanAPIKey(config.DB.QueryRow(
+		"INSERT INTO ApiKeys (UserId, Name, KeyPrefix, KeyHash, Scopes, ExpiresAt) OUTPUT "+insertedColumns(apiKeyColumns)+" VALUES (@p1, @p2, @p3, @p4, @p5, @p6)",
+		userID, name, key[:apiKeyDisplayLength], hashToken(key), strings.Join(scopes, ","), expiresAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: *apiKey, Key: key}, nil
+}
+
+// GetAPIKeys lists the owner's keys, revoked and expired ones included
+func GetAPIKeys(userID int) ([]models.APIKey, error) {
+	rows, err := config.DB.Query(
+		"SELECT "+apiKeyColumns+" FROM ApiKeys WHERE UserId = @p1 ORDER BY CreatedAt DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey stops a key working immediately. Revoked keys stay listed for reference.
+func RevokeAPIKey(userID, keyID int) (*models.APIKey, error) {
+	key, err := scanAPIKey(config.DB.QueryRow(
+		"UPDATE ApiKeys SET RevokedAt = @p1 OUTPUT "+insertedColumns(apiKeyColumns)+" WHERE KeyId = @p2 AND UserId = @p3 AND RevokedAt IS NULL",
+		time.Now(), keyID, userID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("API key not found or already revoked")
+	}
+	return key, err
+}
+
+// ValidateAPIKey resolves a key to its owner. Like sessions, last-used details are only written
+// once a minute at most.
+func ValidateAPIKey(key, ipAddress string) (*models.User, *models.APIKey, error) {
+	apiKey, err := scanAPIKey(config.DB.QueryRow(
+		"SELECT "+apiKeyColumns+" FROM ApiKeys WHERE KeyHash = @p1",
+		hashToken(key),
+	))
+	if err != nil {
+		return nil, nil, errors.New("invalid API key")
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return nil, nil, errors.New("API key has been revoked")
+	}
+	if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+		return nil, nil, errors.New("API key has expired")
+	}
+
+	user, err := GetUserByID(apiKey.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Status == UserStatusSuspended {
+		return nil, nil, errors.New("account is suspended")
+	}
+	if user.Status == UserStatusDeleted {
+		return nil, nil, errors.New("invalid API key")
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= sessionTouchInterval {
+		_, err := config.DB.Exec(
+			"UPDATE ApiKeys SET LastUsedAt = @p1, LastUsedIp = @p2 WHERE KeyId = @p3",
+			now, truncate(ipAddress, 45), apiKey.KeyID,
+		)
+		if err != nil {
+			log.Println("Error recording API key use:", err)
+		}
+	}
+
+	return user, apiKey, nil
+}
+
+// APIKeyHasScope reports whether the key was granted the scope
+func APIKeyHasScope(key *models.APIKey, scope authz.APIKeyScope) bool {
+	for _, s := range key.Scopes {
+		if s == string(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	seen := map[string]bool{}
+	var normalized []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !authz.IsAPIKeyScope(scope) {
+			return nil, errors.New("unknown scope " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+// insertedColumns turns "A, B" into "INSERTED.A, INSERTED.B" for OUTPUT clauses
+func insertedColumns(columns string) string {
+	return "INSERTED." + strings.ReplaceAll(columns, ", ", ", INSERTED.")
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var lastUsedIP sql.NullString
+	err := row.Scan(&key.KeyID, &key.UserID, &key.Name, &key.Prefix, &scopes, &expiresAt, &lastUsedAt, &lastUsedIP, &revokedAt, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Split(scopes, ",")
+	key.LastUsedIP = lastUsedIP.String
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
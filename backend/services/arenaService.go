@@ -8,7 +8,7 @@ import (
 	"time"
 )
 
-func CreateArena(stadiumID int, req models.CreateArenaRequest) (*models.Arena, error) {
+func CreateArena(deps *Deps, stadiumID int, req models.CreateArenaRequest) (*models.Arena, error) {
 	// Verify stadium exists
 	var exists int
 	err := config.DB.QueryRow("SELECT COUNT(*) FROM Stadiums WHERE StadiumId = @p1", stadiumID).Scan(&exists)
@@ -21,26 +21,36 @@ func CreateArena(stadiumID int, req models.CreateArenaRequest) (*models.Arena, e
 		return nil, errors.New("stadium ID mismatch")
 	}
 
+	// Resolve the sport type against the catalog
+	sportType, err := resolveArenaSportType(req)
+	if err != nil {
+		return nil, err
+	}
+
 	result := config.DB.QueryRow(
-		"INSERT INTO Arenas (StadiumId, Name, SportType, Capacity, SlotDuration, Price) OUTPUT INSERTED.ArenaId, INSERTED.StadiumId, INSERTED.Name, INSERTED.SportType, INSERTED.Capacity, INSERTED.SlotDuration, INSERTED.Price, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4, @p5, @p6)",
-		req.StadiumID, req.Name, req.SportType, req.Capacity, req.SlotDuration, req.Price,
+		"INSERT INTO Arenas (StadiumId, Name, SportType, SportTypeId, Description, Capacity, SlotDuration, Price) OUTPUT INSERTED.ArenaId, INSERTED.StadiumId, INSERTED.Name, INSERTED.SportType, INSERTED.SportTypeId, INSERTED.Description, INSERTED.Capacity, INSERTED.SlotDuration, INSERTED.Price, INSERTED.IsPublished, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8)",
+		req.StadiumID, req.Name, sportType.Name, sportType.SportTypeID, req.Description, req.Capacity, req.SlotDuration, req.Price,
 	)
 
 	arena := &models.Arena{}
-	err = result.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+	err = result.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	syncSearchIndexForStadium(arena.StadiumID)
+	invalidateAutocomplete()
+	notifySavedSearchesAsync(deps, arena.ArenaID)
+
 	return arena, nil
 }
 
 func GetArenaByID(arenaID int) (*models.Arena, error) {
 	arena := &models.Arena{}
 	err := config.DB.QueryRow(
-		"SELECT ArenaId, StadiumId, Name, SportType, Capacity, SlotDuration, Price, CreatedAt FROM Arenas WHERE ArenaId = @p1",
+		"SELECT ArenaId, StadiumId, Name, SportType, SportTypeId, Description, Capacity, SlotDuration, Price, IsPublished, CreatedAt FROM Arenas WHERE ArenaId = @p1",
 		arenaID,
-	).Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+	).Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 
 	if err != nil {
 		return nil, errors.New("arena not found")
@@ -57,24 +67,38 @@ func UpdateArena(arenaID int, req models.CreateArenaRequest) (*models.Arena, err
 		return nil, errors.New("arena not found")
 	}
 
+	// Resolve the sport type against the catalog
+	sportType, err := resolveArenaSportType(req)
+	if err != nil {
+		return nil, err
+	}
+
 	result := config.DB.QueryRow(
-		"UPDATE Arenas SET Name = @p1, SportType = @p2, Capacity = @p3, SlotDuration = @p4, Price = @p5 OUTPUT INSERTED.ArenaId, INSERTED.StadiumId, INSERTED.Name, INSERTED.SportType, INSERTED.Capacity, INSERTED.SlotDuration, INSERTED.Price, INSERTED.CreatedAt WHERE ArenaId = @p6",
-		req.Name, req.SportType, req.Capacity, req.SlotDuration, req.Price, arenaID,
+		"UPDATE Arenas SET Name = @p1, SportType = @p2, SportTypeId = @p3, Description = @p4, Capacity = @p5, SlotDuration = @p6, Price = @p7 OUTPUT INSERTED.ArenaId, INSERTED.StadiumId, INSERTED.Name, INSERTED.SportType, INSERTED.SportTypeId, INSERTED.Description, INSERTED.Capacity, INSERTED.SlotDuration, INSERTED.Price, INSERTED.IsPublished, INSERTED.CreatedAt WHERE ArenaId = @p8",
+		req.Name, sportType.Name, sportType.SportTypeID, req.Description, req.Capacity, req.SlotDuration, req.Price, arenaID,
 	)
 
 	arena := &models.Arena{}
-	err = result.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+	err = result.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	syncSearchIndexForStadium(arena.StadiumID)
+	invalidateAutocomplete()
+
 	return arena, nil
 }
 
 func DeleteArena(arenaID int) error {
+	arena, err := GetArenaByID(arenaID)
+	if err != nil {
+		return err
+	}
+
 	// Check if arena has any bookings
 	var bookingCount int
-	err := config.DB.QueryRow("SELECT COUNT(*) FROM Bookings WHERE ArenaId = @p1 AND Status IN ('Pending', 'Confirmed')", arenaID).Scan(&bookingCount)
+	err = config.DB.QueryRow("SELECT COUNT(*) FROM Bookings WHERE ArenaId = @p1 AND Status IN ('Pending', 'Confirmed')", arenaID).Scan(&bookingCount)
 	if err != nil {
 		return err
 	}
@@ -89,12 +113,16 @@ func DeleteArena(arenaID int) error {
 		return err
 	}
 
+	removeFromSearchIndex(arenaSearchID(arenaID))
+	syncSearchIndexForStadium(arena.StadiumID)
+	invalidateAutocomplete()
+
 	return nil
 }
 
 func GetArenasByStadium(stadiumID int) ([]models.Arena, error) {
 	rows, err := config.DB.Query(
-		"SELECT ArenaId, StadiumId, Name, SportType, Capacity, SlotDuration, Price, CreatedAt FROM Arenas WHERE StadiumId = @p1 ORDER BY CreatedAt DESC",
+		"SELECT ArenaId, StadiumId, Name, SportType, SportTypeId, Description, Capacity, SlotDuration, Price, IsPublished, CreatedAt FROM Arenas WHERE StadiumId = @p1 ORDER BY CreatedAt DESC",
 		stadiumID,
 	)
 	if err != nil {
@@ -105,7 +133,7 @@ func GetArenasByStadium(stadiumID int) ([]models.Arena, error) {
 	var arenas []models.Arena
 	for rows.Next() {
 		var arena models.Arena
-		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -157,13 +185,13 @@ func GetArenasByStadiumPaginated(params models.ArenaSearchParams) (*models.Pagin
 		countQuery = "SELECT COUNT(*) FROM Arenas WHERE StadiumId = @p1 AND (Name LIKE @p2 OR SportType LIKE @p2)"
 		countArgs = []interface{}{params.StadiumID, searchPattern}
 
-		query = fmt.Sprintf("SELECT ArenaId, StadiumId, Name, SportType, Capacity, SlotDuration, Price, CreatedAt FROM Arenas WHERE StadiumId = @p1 AND (Name LIKE @p2 OR SportType LIKE @p2) ORDER BY %s %s OFFSET @p3 ROWS FETCH NEXT @p4 ROWS ONLY", sortColumn, sortDirection)
+		query = fmt.Sprintf("SELECT ArenaId, StadiumId, Name, SportType, SportTypeId, Description, Capacity, SlotDuration, Price, IsPublished, CreatedAt FROM Arenas WHERE StadiumId = @p1 AND (Name LIKE @p2 OR SportType LIKE @p2) ORDER BY %s %s OFFSET @p3 ROWS FETCH NEXT @p4 ROWS ONLY", sortColumn, sortDirection)
 		queryArgs = []interface{}{params.StadiumID, searchPattern, offset, params.PageSize}
 	} else {
 		countQuery = "SELECT COUNT(*) FROM Arenas WHERE StadiumId = @p1"
 		countArgs = []interface{}{params.StadiumID}
 
-		query = fmt.Sprintf("SELECT ArenaId, StadiumId, Name, SportType, Capacity, SlotDuration, Price, CreatedAt FROM Arenas WHERE StadiumId = @p1 ORDER BY %s %s OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY", sortColumn, sortDirection)
+		query = fmt.Sprintf("SELECT ArenaId, StadiumId, Name, SportType, SportTypeId, Description, Capacity, SlotDuration, Price, IsPublished, CreatedAt FROM Arenas WHERE StadiumId = @p1 ORDER BY %s %s OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY", sortColumn, sortDirection)
 		queryArgs = []interface{}{params.StadiumID, offset, params.PageSize}
 	}
 
@@ -197,7 +225,7 @@ func GetArenasByStadiumPaginated(params models.ArenaSearchParams) (*models.Pagin
 	var arenas []models.Arena
 	for rows.Next() {
 		var arena models.Arena
-		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -217,7 +245,7 @@ func GetArenasByStadiumPaginated(params models.ArenaSearchParams) (*models.Pagin
 
 func GetAllArenas() ([]models.Arena, error) {
 	rows, err := config.DB.Query(
-		"SELECT ArenaId, StadiumId, Name, SportType, Capacity, SlotDuration, Price, CreatedAt FROM Arenas ORDER BY CreatedAt DESC",
+		"SELECT ArenaId, StadiumId, Name, SportType, SportTypeId, Description, Capacity, SlotDuration, Price, IsPublished, CreatedAt FROM Arenas WHERE IsPublished = 1 AND StadiumId IN (SELECT StadiumId FROM Stadiums WHERE IsPublished = 1 AND Status = 'Approved') ORDER BY CreatedAt DESC",
 	)
 	if err != nil {
 		return nil, err
@@ -227,7 +255,7 @@ func GetAllArenas() ([]models.Arena, error) {
 	var arenas []models.Arena
 	for rows.Next() {
 		var arena models.Arena
-		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -239,10 +267,11 @@ func GetAllArenas() ([]models.Arena, error) {
 
 func GetAllArenasWithLocation() ([]models.ArenaWithLocation, error) {
 	query := `
-		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.Capacity, a.SlotDuration, a.Price, a.CreatedAt,
+		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.SportTypeId, a.Description, a.Capacity, a.SlotDuration, a.Price, a.IsPublished, a.CreatedAt,
 		       s.Name AS StadiumName, s.Location
 		FROM Arenas a
 		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
 		ORDER BY a.CreatedAt DESC
 	`
 
@@ -256,8 +285,8 @@ func GetAllArenasWithLocation() ([]models.ArenaWithLocation, error) {
 	for rows.Next() {
 		var arena models.ArenaWithLocation
 		err := rows.Scan(
-			&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType,
-			&arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt,
+			&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description,
+			&arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt,
 			&arena.StadiumName, &arena.Location,
 		)
 		if err != nil {
@@ -271,11 +300,12 @@ func GetAllArenasWithLocation() ([]models.ArenaWithLocation, error) {
 
 func GetArenasByFilters(location, sportType string, date *time.Time) ([]models.Arena, error) {
 	query := `
-		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.Capacity, a.SlotDuration, a.Price, a.CreatedAt
+		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.SportTypeId, a.Description, a.Capacity, a.SlotDuration, a.Price, a.IsPublished, a.CreatedAt
 		FROM Arenas a
 		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
-		WHERE (@p1 = '' OR s.Location LIKE '%' + @p1 + '%')
-		  AND (@p2 = '' OR a.SportType = @p2)
+		WHERE a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+		  AND (@p1 = '' OR s.Location LIKE '%' + @p1 + '%')
+		  AND (@p2 = '' OR a.SportTypeId IN (SELECT SportTypeId FROM dbo.MatchSportTypes(@p2)))
 		ORDER BY a.CreatedAt DESC
 	`
 
@@ -288,7 +318,7 @@ func GetArenasByFilters(location, sportType string, date *time.Time) ([]models.A
 	var arenas []models.Arena
 	for rows.Next() {
 		var arena models.Arena
-		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt)
+		err := rows.Scan(&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description, &arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -300,12 +330,13 @@ func GetArenasByFilters(location, sportType string, date *time.Time) ([]models.A
 
 func GetArenasByFiltersWithLocation(location, sportType string, date *time.Time) ([]models.ArenaWithLocation, error) {
 	query := `
-		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.Capacity, a.SlotDuration, a.Price, a.CreatedAt,
+		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.SportTypeId, a.Description, a.Capacity, a.SlotDuration, a.Price, a.IsPublished, a.CreatedAt,
 		       s.Name AS StadiumName, s.Location
 		FROM Arenas a
 		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
-		WHERE (@p1 = '' OR s.Location LIKE '%' + @p1 + '%')
-		  AND (@p2 = '' OR a.SportType = @p2)
+		WHERE a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+		  AND (@p1 = '' OR s.Location LIKE '%' + @p1 + '%')
+		  AND (@p2 = '' OR a.SportTypeId IN (SELECT SportTypeId FROM dbo.MatchSportTypes(@p2)))
 		ORDER BY a.CreatedAt DESC
 	`
 
@@ -319,8 +350,8 @@ func GetArenasByFiltersWithLocation(location, sportType string, date *time.Time)
 	for rows.Next() {
 		var arena models.ArenaWithLocation
 		err := rows.Scan(
-			&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType,
-			&arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.CreatedAt,
+			&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description,
+			&arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt,
 			&arena.StadiumName, &arena.Location,
 		)
 		if err != nil {
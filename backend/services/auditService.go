@@ -0,0 +1,245 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	AuditEntityUser       = "User"
+	AuditEntityStadium    = "Stadium"
+	AuditEntityArena      = "Arena"
+	AuditEntityBooking    = "Booking"
+	AuditEntityMembership = "StadiumMember"
+	AuditEntityTransfer   = "StadiumTransfer"
+	AuditEntityLockout    = "AccountLockout"
+	AuditEntityAPIKey     = "ApiKey"
+)
+
+const (
+	AuditActionUserSignup         = "user.signup"
+	AuditActionUserLogin          = "user.login"
+	AuditActionUserLoginFailed    = "user.login_failed"
+	AuditActionUserLogout         = "user.logout"
+	AuditActionUserEmailVerified  = "user.verify_email"
+	AuditActionUserPasswordReset  = "user.password_reset"
+	AuditActionUserPasswordChange = "user.password_change"
+	AuditActionUserProfileUpdate  = "user.profile_update"
+	AuditActionUserEmailChanged   = "user.email_change"
+	AuditActionUserDeleted        = "user.delete"
+	AuditActionUserDataExport     = "user.export"
+	AuditActionSessionRevoked     = "user.session_revoke"
+	AuditActionTwoFactorEnabled   = "user.2fa_enable"
+	AuditActionTwoFactorDisabled  = "user.2fa_disable"
+	AuditActionRecoveryCodesReset = "user.2fa_recovery_codes"
+	AuditActionLoginChallenge     = "user.login_challenge"
+	AuditActionIdentityLinked     = "user.identity_link"
+	AuditActionLockoutCleared     = "user.lockout_clear"
+	AuditActionAPIKeyCreated      = "apikey.create"
+	AuditActionAPIKeyRevoked      = "apikey.revoke"
+	AuditActionUserSuspended      = "user.suspend"
+	AuditActionUserReactivated    = "user.reactivate"
+	AuditActionUserRoleChanged    = "user.role"
+	AuditActionStadiumCreated     = "stadium.create"
+	AuditActionStadiumUpdated     = "stadium.update"
+	AuditActionStadiumDeleted     = "stadium.delete"
+	AuditActionStadiumPublished   = "stadium.publish"
+	AuditActionStadiumUnpublished = "stadium.unpublish"
+	AuditActionStadiumSubmitted   = "stadium.submit"
+	AuditActionStadiumApproved    = "stadium.approve"
+	AuditActionStadiumRejected    = "stadium.reject"
+	AuditActionStadiumSecurity    = "stadium.security"
+	AuditActionTransferRequested  = "stadium.transfer_request"
+	AuditActionTransferAccepted   = "stadium.transfer_accept"
+	AuditActionTransferDeclined   = "stadium.transfer_decline"
+	AuditActionTransferCancelled  = "stadium.transfer_cancel"
+	AuditActionArenaCreated       = "arena.create"
+	AuditActionArenaUpdated       = "arena.update"
+	AuditActionArenaDeleted       = "arena.delete"
+	AuditActionArenaPublished     = "arena.publish"
+	AuditActionArenaUnpublished   = "arena.unpublish"
+	AuditActionBookingCreated     = "booking.create"
+	AuditActionBookingWalkIn      = "booking.walk_in"
+	AuditActionBookingStatus      = "booking.status"
+	AuditActionBookingCancelled   = "booking.cancel"
+	AuditActionBookingCheckIn     = "booking.check_in"
+	AuditActionBookingForceCancel = "booking.force_cancel"
+	AuditActionMemberInvited      = "member.invite"
+	AuditActionMemberRoleChanged  = "member.role"
+	AuditActionMemberRemoved      = "member.remove"
+	AuditActionMemberAccepted     = "member.accept"
+	AuditActionMemberLeft         = "member.leave"
+)
+
+const (
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 500
+)
+
+// auditFailures counts entries RecordAudit failed to write, so lost entries show up in /debug/info
+var auditFailures atomic.Int64
+
+// execer is what recordAuditEntry writes through: the database, or a transaction so the entry
+// commits or rolls back with the change it describes
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// RecordAudit appends an entry to the audit log. The table rejects updates and deletes,
+// so an entry cannot be changed once written. The change it describes has already been made,
+// so a failure is counted in AuditFailures as well as returned.
+func RecordAudit(event models.AuditEvent) error {
+	err := recordAuditEntry(config.DB, event)
+	if err != nil {
+		auditFailures.Add(1)
+	}
+	return err
+}
+
+// AuditFailures is how many entries RecordAudit has failed to write since startup
+func AuditFailures() int64 {
+	return auditFailures.Load()
+}
+
+func recordAuditEntry(db execer, event models.AuditEvent) error {
+	changes, err := auditJSON(DiffAuditSnapshots(event.Before, event.After))
+	if err != nil {
+		return err
+	}
+	details, err := auditJSON(event.Details)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO AuditLog (ActorId, Action, EntityType, EntityId, StadiumId, Changes, Details, IpAddress)
+		VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8)
+	`,
+		nullableID(event.ActorID), event.Action, event.EntityType, nullableID(event.EntityID),
+		nullableID(event.StadiumID), changes, details, sql.NullString{String: event.IPAddress, Valid: event.IPAddress != ""},
+	)
+	return err
+}
+
+// DiffAuditSnapshots compares two snapshots by their JSON fields and returns the fields that differ.
+// A nil before records every field of after as added, and a nil after records every field as removed.
+func DiffAuditSnapshots(before, after interface{}) map[string]models.FieldChange {
+	if before == nil && after == nil {
+		return nil
+	}
+
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := make(map[string]models.FieldChange)
+	for name, value := range beforeFields {
+		if next, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, next) {
+			changes[name] = models.FieldChange{From: value, To: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = models.FieldChange{To: value}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func GetAuditLog(params models.AuditLogParams) ([]models.AuditEntry, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultAuditLogLimit
+	}
+	if params.Limit > maxAuditLogLimit {
+		params.Limit = maxAuditLogLimit
+	}
+
+	// OwnerID scopes the log to stadiums the user currently owns
+	rows, err := config.DB.Query(`
+		SELECT TOP (@p9) AuditId, ActorId, Action, EntityType, EntityId, StadiumId, Changes, Details, IpAddress, CreatedAt
+		FROM AuditLog
+		WHERE (@p1 = 0 OR ActorId = @p1)
+		  AND (@p2 = '' OR EntityType = @p2)
+		  AND (@p3 = 0 OR EntityId = @p3)
+		  AND (@p4 = '' OR Action = @p4)
+		  AND (@p5 = 0 OR StadiumId = @p5)
+		  AND (@p6 = 0 OR StadiumId IN (SELECT StadiumId FROM Stadiums WHERE OwnerId = @p6))
+		  AND (@p7 IS NULL OR CreatedAt >= @p7)
+		  AND (@p8 IS NULL OR CreatedAt < @p8)
+		ORDER BY CreatedAt DESC, AuditId DESC
+	`, params.ActorID, params.EntityType, params.EntityID, params.Action, params.StadiumID, params.OwnerID,
+		nullableTime(params.From), nullableTime(params.To), params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		var actorID, entityID, stadiumID sql.NullInt64
+		var changes, details, ipAddress sql.NullString
+		err := rows.Scan(&entry.AuditID, &actorID, &entry.Action, &entry.EntityType, &entityID, &stadiumID,
+			&changes, &details, &ipAddress, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.ActorID = int(actorID.Int64)
+		entry.EntityID = int(entityID.Int64)
+		entry.StadiumID = int(stadiumID.Int64)
+		entry.IPAddress = ipAddress.String
+		if changes.Valid {
+			entry.Changes = json.RawMessage(changes.String)
+		}
+		if details.Valid {
+			entry.Details = json.RawMessage(details.String)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// auditFields flattens a snapshot into its top-level JSON fields so that json:"-" fields
+// such as password hashes never reach the log
+func auditFields(snapshot interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Ptr && reflect.ValueOf(snapshot).IsNil() {
+		return fields
+	}
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(encoded, &fields)
+	return fields
+}
+
+func auditJSON(value interface{}) (sql.NullString, error) {
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Map && reflect.ValueOf(value).IsNil() {
+		return sql.NullString{}, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+func nullableID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id > 0}
+}
+
+func nullableTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
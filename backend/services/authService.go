@@ -1,16 +1,27 @@
 package services
 
 import (
+	"BookMyArena/backend/authz"
 	"BookMyArena/backend/config"
 	"BookMyArena/backend/models"
 	"crypto/rand"
+	"database/sql"
 	"encoding/hex"
 	"errors"
+	"log"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	UserStatusActive    = "Active"
+	UserStatusSuspended = "Suspended"
+)
+
+// userColumns is the column list scanUser expects, in order
+const userColumns = "UserId, FullName, Email, PasswordHash, Role, Status, EmailVerified, TwoFactorEnabled, CreatedAt"
+
 func HashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -24,7 +35,7 @@ func VerifyPassword(hashedPassword, password string) bool {
 	return err == nil
 }
 
-func CreateUser(req models.SignupRequest) (*models.User, error) {
+func CreateUser(deps *Deps, req models.SignupRequest) (*models.User, error) {
 	// Check if email already exists
 	var count int
 	err := config.DB.QueryRow("SELECT COUNT(*) FROM Users WHERE Email = @p1", req.Email).Scan(&count)
@@ -36,7 +47,7 @@ func CreateUser(req models.SignupRequest) (*models.User, error) {
 	}
 
 	// Validate role
-	if req.Role != "Owner" && req.Role != "User" {
+	if !authz.IsSignupRole(req.Role) {
 		return nil, errors.New("invalid role. Must be 'Owner' or 'User'")
 	}
 
@@ -48,35 +59,67 @@ func CreateUser(req models.SignupRequest) (*models.User, error) {
 
 	// Insert user
 	result := config.DB.QueryRow(
-		"INSERT INTO Users (FullName, Email, PasswordHash, Role) OUTPUT INSERTED.UserId, INSERTED.FullName, INSERTED.Email, INSERTED.Role, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4)",
+		"INSERT INTO Users (FullName, Email, PasswordHash, Role) OUTPUT INSERTED.UserId, INSERTED.FullName, INSERTED.Email, INSERTED.PasswordHash, INSERTED.Role, INSERTED.Status, INSERTED.EmailVerified, INSERTED.TwoFactorEnabled, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4)",
 		req.FullName, req.Email, passwordHash, req.Role,
 	)
 
-	user := &models.User{}
-	err = result.Scan(&user.UserID, &user.FullName, &user.Email, &user.Role, &user.CreatedAt)
+	user, err := scanUser(result)
 	if err != nil {
 		return nil, err
 	}
 
+	// The account exists either way; the user can ask for another email if this one fails
+	if err := SendVerificationEmail(deps, user); err != nil {
+		log.Println("Error sending verification email:", err)
+	}
+
 	return user, nil
 }
 
-func AuthenticateUser(email, password string) (*models.User, error) {
-	user := &models.User{}
-	err := config.DB.QueryRow(
-		"SELECT UserId, FullName, Email, PasswordHash, Role, CreatedAt FROM Users WHERE Email = @p1",
-		email,
-	).Scan(&user.UserID, &user.FullName, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt)
-
+// AuthenticateUser checks the password. For an account with two-factor authentication it also returns
+// a login challenge token; the caller must not create a session until CompleteLoginChallenge succeeds.
+// Failures count towards the throttle for the email and the client address; callers check LoginRetryAfter first.
+// The failure streak is cleared only when the login is complete, so after the second factor for 2FA accounts.
+func AuthenticateUser(deps *Deps, email, password, ipAddress string) (*models.User, string, error) {
+	user, err := scanUser(config.DB.QueryRow("SELECT "+userColumns+" FROM Users WHERE Email = @p1", email))
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		if err != sql.ErrNoRows {
+			log.Println("Error looking up user for login:", err)
+			return nil, "", errors.New("invalid email or password")
+		}
+		compareDummyPassword(password)
+		recordLoginFailure(deps, email, ipAddress, nil)
+		return nil, "", errors.New("invalid email or password")
+	}
+
+	// Accounts created through an external provider have no password
+	if user.PasswordHash == "" {
+		compareDummyPassword(password)
+		recordLoginFailure(deps, email, ipAddress, user)
+		return nil, "", errors.New("invalid email or password")
 	}
 
 	if !VerifyPassword(user.PasswordHash, password) {
-		return nil, errors.New("invalid email or password")
+		recordLoginFailure(deps, email, ipAddress, user)
+		return nil, "", errors.New("invalid email or password")
 	}
 
-	return user, nil
+	if user.Status == UserStatusSuspended {
+		return nil, "", errors.New("account is suspended")
+	}
+
+	// With 2FA pending, CompleteLoginChallenge clears the streak
+	if !user.TwoFactorEnabled {
+		recordLoginSuccess(email)
+		return user, "", nil
+	}
+
+	challengeToken, err := StartLoginChallenge(user.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, challengeToken, nil
 }
 
 func GenerateSessionToken() (string, error) {
@@ -87,59 +130,117 @@ func GenerateSessionToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func CreateSession(userID int) (string, error) {
+// CreateSession starts a session for the client described by opts. Its idle and absolute deadlines
+// come from the session policy, with longer ones for "remember me".
+func CreateSession(deps *Deps, userID int, opts models.SessionOptions) (*models.Session, error) {
 	token, err := GenerateSessionToken()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	expiresAt := time.Now().Add(24 * time.Hour)
+	idle, absolute := deps.sessionTimeouts(opts.RememberMe)
+	now := time.Now()
+	session := &models.Session{
+		UserID:            userID,
+		Token:             token,
+		UserAgent:         truncate(opts.UserAgent, maxUserAgentLength),
+		IPAddress:         opts.IPAddress,
+		RememberMe:        opts.RememberMe,
+		CreatedAt:         now,
+		LastSeenAt:        now,
+		ExpiresAt:         minTime(now.Add(idle), now.Add(absolute)),
+		AbsoluteExpiresAt: now.Add(absolute),
+	}
 
-	_, err = config.DB.Exec(
-		"INSERT INTO Sessions (UserId, Token, ExpiresAt) VALUES (@p1, @p2, @p3)",
-		userID, token, expiresAt,
-	)
+	err = config.DB.QueryRow(`
+		INSERT INTO Sessions (UserId, Token, UserAgent, IpAddress, RememberMe, CreatedAt, LastSeenAt, ExpiresAt, AbsoluteExpiresAt)
+		OUTPUT INSERTED.SessionId
+		VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p6, @p7, @p8)
+	`, userID, token, session.UserAgent, session.IPAddress, session.RememberMe, now, session.ExpiresAt, session.AbsoluteExpiresAt).Scan(&session.SessionID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
+	return session, nil
 }
 
-func ValidateSession(token string) (*models.User, error) {
-	var userID int
-	var expiresAt time.Time
+// ValidateSession resolves a token to its user and slides the idle deadline forward,
+// never past the session's absolute deadline. Both lookups go through the cache.
+func ValidateSession(deps *Deps, token string) (*models.User, error) {
+	now := time.Now()
+
+	session, ok := cachedSessionFor(token)
+	if !ok || session.expired(now) {
+		// A cached deadline may be stale if another instance has since extended it, so only the database decides expiry
+		var err error
+		session, err = loadSession(token)
+		if err != nil {
+			return nil, errors.New("invalid session")
+		}
+	}
 
-	err := config.DB.QueryRow(
-		"SELECT UserId, ExpiresAt FROM Sessions WHERE Token = @p1",
-		token,
-	).Scan(&userID, &expiresAt)
+	if session.expired(now) {
+		// Delete expired session
+		DeleteSession(token)
+		return nil, errors.New("session expired")
+	}
 
+	user, err := deps.sessionUser(session.UserID)
 	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == UserStatusSuspended {
+		return nil, errors.New("account is suspended")
+	}
+	if user.Status == UserStatusDeleted {
 		return nil, errors.New("invalid session")
 	}
 
-	if time.Now().After(expiresAt) {
-		// Delete expired session
-		config.DB.Exec("DELETE FROM Sessions WHERE Token = @p1", token)
-		return nil, errors.New("session expired")
+	// Touching the row on every request would turn reads into writes, so only refresh once in a while
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		idle, _ := deps.sessionTimeouts(session.RememberMe)
+		newExpiry := now.Add(idle)
+		if session.AbsoluteExpiresAt != nil {
+			newExpiry = minTime(newExpiry, *session.AbsoluteExpiresAt)
+		}
+		_, err := config.DB.Exec(
+			"UPDATE Sessions SET LastSeenAt = @p1, ExpiresAt = @p2 WHERE Token = @p3",
+			now, newExpiry, token,
+		)
+		if err != nil {
+			log.Println("Error refreshing session:", err)
+		} else {
+			session.LastSeenAt = now
+			session.ExpiresAt = newExpiry
+		}
 	}
+	deps.cacheSession(token, session)
 
-	user := &models.User{}
-	err = config.DB.QueryRow(
-		"SELECT UserId, FullName, Email, PasswordHash, Role, CreatedAt FROM Users WHERE UserId = @p1",
-		userID,
-	).Scan(&user.UserID, &user.FullName, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	return user, nil
+}
 
+func GetUserByID(userID int) (*models.User, error) {
+	user, err := scanUser(config.DB.QueryRow("SELECT "+userColumns+" FROM Users WHERE UserId = @p1", userID))
 	if err != nil {
-		return nil, err
+		return nil, errors.New("user not found")
 	}
 
 	return user, nil
 }
 
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(&user.UserID, &user.FullName, &user.Email, &user.PasswordHash, &user.Role, &user.Status, &user.EmailVerified, &user.TwoFactorEnabled, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func DeleteSession(token string) error {
 	_, err := config.DB.Exec("DELETE FROM Sessions WHERE Token = @p1", token)
+	invalidateCachedSessions(token)
 	return err
 }
 
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+)
+
+// Authorize reports whether the user's account role grants the permission
+func Authorize(user *models.User, permission authz.Permission) bool {
+	return user != nil && authz.RoleHas(user.Role, permission)
+}
+
+// AuthorizeStadium reports whether the user holds the permission on one stadium,
+// either as its owner or through an active staff membership. Staff of a stadium that
+// requires two-factor authentication get nothing until they turn it on.
+func AuthorizeStadium(userID, stadiumID int, permission authz.Permission) bool {
+	role, ok := stadiumRoleFor(stadiumID, userID)
+	return ok && authz.StadiumRoleHas(role, permission)
+}
+
+func stadiumRoleFor(stadiumID, userID int) (string, bool) {
+	if VerifyStadiumOwner(stadiumID, userID) {
+		return authz.StadiumRoleOwner, true
+	}
+
+	var role string
+	err := config.DB.QueryRow(`
+		SELECT m.Role
+		FROM StadiumMembers m
+		INNER JOIN Stadiums s ON s.StadiumId = m.StadiumId
+		INNER JOIN Users u ON u.UserId = m.UserId
+		WHERE m.StadiumId = @p1 AND m.UserId = @p2 AND m.Status = 'Active'
+		AND (s.RequireStaffTwoFactor = 0 OR u.TwoFactorEnabled = 1)
+	`, stadiumID, userID).Scan(&role)
+	if err != nil {
+		return "", false
+	}
+
+	return role, true
+}
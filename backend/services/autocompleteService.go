@@ -0,0 +1,261 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/jobs"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/search"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SuggestionTypeLocation  = "location"
+	SuggestionTypeStadium   = "stadium"
+	SuggestionTypeArena     = "arena"
+	SuggestionTypeSportType = "sportType"
+
+	defaultAutocompleteLimit = 8
+	maxAutocompleteLimit     = 25
+
+	autocompleteMaxAge = 5 * time.Minute
+)
+
+var (
+	autocompleteSuggester = search.NewSuggester()
+
+	autocompleteMu         sync.Mutex
+	autocompleteBuiltAt    time.Time
+	autocompleteStale      bool
+	autocompleteRebuilding bool
+)
+
+func Autocomplete(prefix string, types []string, limit int) ([]models.AutocompleteSuggestion, error) {
+	if limit < 1 {
+		limit = defaultAutocompleteLimit
+	}
+	if limit > maxAutocompleteLimit {
+		limit = maxAutocompleteLimit
+	}
+
+	allowed := make(map[string]bool)
+	for _, t := range types {
+		switch t {
+		case SuggestionTypeLocation, SuggestionTypeStadium, SuggestionTypeArena, SuggestionTypeSportType:
+			allowed[t] = true
+		case "":
+		default:
+			return nil, errors.New("invalid suggestion type: " + t)
+		}
+	}
+
+	if err := ensureAutocompleteIndex(); err != nil {
+		return nil, err
+	}
+
+	var accept func(payload interface{}) bool
+	if len(allowed) > 0 {
+		accept = func(payload interface{}) bool {
+			return allowed[payload.(models.AutocompleteSuggestion).Type]
+		}
+	}
+
+	suggestions := []models.AutocompleteSuggestion{}
+	for _, match := range autocompleteSuggester.Suggest(prefix, limit, accept) {
+		suggestion := match.Payload.(models.AutocompleteSuggestion)
+		suggestion.Score = math.Round(match.Score*1000) / 1000
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+// invalidateAutocomplete starts a rebuild on the next keystroke after stadiums or arenas change
+func invalidateAutocomplete() {
+	autocompleteMu.Lock()
+	autocompleteStale = true
+	autocompleteMu.Unlock()
+}
+
+func ensureAutocompleteIndex() error {
+	autocompleteMu.Lock()
+	builtAt := autocompleteBuiltAt
+	stale := !builtAt.IsZero() && (autocompleteStale || time.Since(builtAt) > autocompleteMaxAge) && !autocompleteRebuilding
+	if stale {
+		autocompleteRebuilding = true
+	}
+	autocompleteMu.Unlock()
+
+	if builtAt.IsZero() {
+		return rebuildAutocompleteIndex()
+	}
+
+	// Keep serving the current suggestions while they are rebuilt; this endpoint is hit on every keystroke
+	if stale {
+		jobs.Go("autocomplete rebuild", func() {
+			defer func() {
+				autocompleteMu.Lock()
+				autocompleteRebuilding = false
+				autocompleteMu.Unlock()
+			}()
+			if err := rebuildAutocompleteIndex(); err != nil {
+				log.Println("Error rebuilding autocomplete index:", err)
+			}
+		})
+	}
+
+	return nil
+}
+
+// rebuildAutocompleteIndex loads the suggestions without holding autocompleteMu and swaps them in.
+// A write during the load marks the index stale again, so it is picked up by the next rebuild.
+func rebuildAutocompleteIndex() error {
+	autocompleteMu.Lock()
+	autocompleteStale = false
+	autocompleteMu.Unlock()
+
+	suggestions, err := loadAutocompleteSuggestions()
+	if err != nil {
+		autocompleteMu.Lock()
+		autocompleteStale = true
+		autocompleteMu.Unlock()
+		return err
+	}
+
+	autocompleteSuggester.Replace(suggestions)
+
+	autocompleteMu.Lock()
+	autocompleteBuiltAt = time.Now()
+	autocompleteMu.Unlock()
+
+	return nil
+}
+
+func loadAutocompleteSuggestions() ([]search.Suggestion, error) {
+	var suggestions []search.Suggestion
+
+	// Popularity is the number of arenas behind a suggestion, on a log scale
+	popularity := func(count int) float64 {
+		return 1 + math.Log1p(float64(count))
+	}
+
+	stadiumRows, err := config.DB.Query(`
+		SELECT s.StadiumId, s.Name, s.Location, COUNT(a.ArenaId) AS ArenaCount
+		FROM Stadiums s
+		LEFT JOIN Arenas a ON a.StadiumId = s.StadiumId AND a.IsPublished = 1
+		WHERE s.IsPublished = 1 AND s.Status = 'Approved'
+		GROUP BY s.StadiumId, s.Name, s.Location
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stadiumRows.Close()
+
+	locationCounts := make(map[string]int)
+	locationNames := make(map[string]string)
+	for stadiumRows.Next() {
+		var stadiumID, arenaCount int
+		var name, location string
+		if err := stadiumRows.Scan(&stadiumID, &name, &location, &arenaCount); err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, search.Suggestion{
+			Text:    name,
+			Weight:  popularity(arenaCount),
+			Payload: models.AutocompleteSuggestion{Text: name, Type: SuggestionTypeStadium, ID: stadiumID},
+		})
+
+		// Offer both the full location and each comma-separated part ("Gulberg, Lahore" -> "Lahore")
+		parts := []string{location}
+		if strings.Contains(location, ",") {
+			parts = append(parts, strings.Split(location, ",")...)
+		}
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			key := strings.ToLower(part)
+			locationCounts[key] += arenaCount + 1
+			if _, ok := locationNames[key]; !ok {
+				locationNames[key] = part
+			}
+		}
+	}
+
+	for key, count := range locationCounts {
+		text := locationNames[key]
+		suggestions = append(suggestions, search.Suggestion{
+			Text:    text,
+			Weight:  popularity(count),
+			Payload: models.AutocompleteSuggestion{Text: text, Type: SuggestionTypeLocation},
+		})
+	}
+
+	arenaRows, err := config.DB.Query(`
+		SELECT a.ArenaId, a.Name
+		FROM Arenas a
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer arenaRows.Close()
+
+	for arenaRows.Next() {
+		var arenaID int
+		var name string
+		if err := arenaRows.Scan(&arenaID, &name); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, search.Suggestion{
+			Text:    name,
+			Weight:  1,
+			Payload: models.AutocompleteSuggestion{Text: name, Type: SuggestionTypeArena, ID: arenaID},
+		})
+	}
+
+	// Sport types are suggested by canonical name but also found through their aliases
+	sportTypes, err := GetSportTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	sportCounts := make(map[int]int)
+	countRows, err := config.DB.Query(`
+		SELECT a.SportTypeId, COUNT(*)
+		FROM Arenas a
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+		GROUP BY a.SportTypeId
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer countRows.Close()
+
+	for countRows.Next() {
+		var sportTypeID, count int
+		if err := countRows.Scan(&sportTypeID, &count); err != nil {
+			return nil, err
+		}
+		sportCounts[sportTypeID] = count
+	}
+
+	for _, sportType := range sportTypes {
+		suggestions = append(suggestions, search.Suggestion{
+			Text:     sportType.Name,
+			Keywords: sportType.Aliases,
+			Weight:   popularity(sportCounts[sportType.SportTypeID]),
+			Payload:  models.AutocompleteSuggestion{Text: sportType.Name, Type: SuggestionTypeSportType, ID: sportType.SportTypeID},
+		})
+	}
+
+	return suggestions, nil
+}
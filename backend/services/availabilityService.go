@@ -0,0 +1,241 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	defaultSlotSearchDays  = 7
+	maxSlotSearchDays      = 31
+	defaultSlotSearchLimit = 10
+	maxSlotSearchLimit     = 50
+)
+
+func GenerateSlotAvailability(deps *Deps, arena *models.Arena, date time.Time, bookings []models.Booking) []models.SlotAvailability {
+	// Generate slots for the bookable part of the day (8 AM to 10 PM by default), based on slot duration
+	slotDuration := time.Duration(arena.SlotDuration) * time.Minute
+	if slotDuration <= 0 {
+		return nil
+	}
+
+	var slots []models.SlotAvailability
+
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), deps.slots.DayStartHour, 0, 0, 0, time.UTC)
+	dayEnd := time.Date(date.Year(), date.Month(), date.Day(), deps.slots.DayEndHour, 0, 0, 0, time.UTC)
+
+	currentSlot := dayStart
+	for currentSlot.Add(slotDuration).Before(dayEnd) || currentSlot.Add(slotDuration).Equal(dayEnd) {
+		slotEnd := currentSlot.Add(slotDuration)
+
+		// Check if this slot conflicts with any booking
+		available := true
+		for _, booking := range bookings {
+			if booking.Status == "Cancelled" {
+				continue
+			}
+			// Slots are half-open, so a booking ending exactly when this slot starts doesn't overlap it
+			if currentSlot.Before(booking.SlotEnd) && slotEnd.After(booking.SlotStart) {
+				available = false
+				break
+			}
+		}
+
+		slots = append(slots, models.SlotAvailability{
+			SlotStart: currentSlot,
+			SlotEnd:   slotEnd,
+			Available: available,
+		})
+
+		currentSlot = slotEnd
+	}
+
+	return slots
+}
+
+func FindNextAvailableSlots(deps *Deps, params models.SlotSearchParams) ([]models.AvailableSlot, error) {
+	// Never offer slots that have already started
+	now := time.Now().UTC()
+	if params.From.IsZero() || params.From.Before(now) {
+		params.From = now
+	}
+	if params.To.IsZero() {
+		params.To = params.From.AddDate(0, 0, defaultSlotSearchDays)
+	}
+	if params.To.Sub(params.From) > maxSlotSearchDays*24*time.Hour {
+		params.To = params.From.AddDate(0, 0, maxSlotSearchDays)
+	}
+	if !params.To.After(params.From) {
+		return nil, errors.New("invalid time window")
+	}
+
+	if params.Duration < 0 {
+		return nil, errors.New("invalid duration")
+	}
+
+	if params.Limit < 1 {
+		params.Limit = defaultSlotSearchLimit
+	}
+	if params.Limit > maxSlotSearchLimit {
+		params.Limit = maxSlotSearchLimit
+	}
+
+	arenas, err := getArenasForSlotSearch(params)
+	if err != nil {
+		return nil, err
+	}
+
+	bookingsByArena, err := getBookingsForSlotSearch(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []models.AvailableSlot
+	for i := range arenas {
+		arenaSlots := findArenaAvailableSlots(deps, &arenas[i], bookingsByArena[arenas[i].ArenaID], params)
+		slots = append(slots, arenaSlots...)
+	}
+
+	// Earliest first; cheaper arena wins a tie
+	sort.Slice(slots, func(i, j int) bool {
+		if !slots[i].SlotStart.Equal(slots[j].SlotStart) {
+			return slots[i].SlotStart.Before(slots[j].SlotStart)
+		}
+		if slots[i].Price != slots[j].Price {
+			return slots[i].Price < slots[j].Price
+		}
+		return slots[i].ArenaID < slots[j].ArenaID
+	})
+
+	if len(slots) > params.Limit {
+		slots = slots[:params.Limit]
+	}
+
+	return slots, nil
+}
+
+func findArenaAvailableSlots(deps *Deps, arena *models.ArenaWithLocation, bookings []models.Booking, params models.SlotSearchParams) []models.AvailableSlot {
+	if arena.SlotDuration <= 0 {
+		return nil
+	}
+
+	// A requested duration longer than the arena's slot is served by consecutive slots
+	slotsNeeded := 1
+	if params.Duration > arena.SlotDuration {
+		slotsNeeded = (params.Duration + arena.SlotDuration - 1) / arena.SlotDuration
+	}
+
+	var found []models.AvailableSlot
+
+	day := time.Date(params.From.Year(), params.From.Month(), params.From.Day(), 0, 0, 0, 0, time.UTC)
+	for !day.After(params.To) && len(found) < params.Limit {
+		daySlots := GenerateSlotAvailability(deps, &arena.Arena, day, bookings)
+
+		for i := 0; i+slotsNeeded <= len(daySlots) && len(found) < params.Limit; i++ {
+			slotStart := daySlots[i].SlotStart
+			slotEnd := daySlots[i+slotsNeeded-1].SlotEnd
+			if slotStart.Before(params.From) || slotEnd.After(params.To) {
+				continue
+			}
+
+			available := true
+			for _, slot := range daySlots[i : i+slotsNeeded] {
+				if !slot.Available {
+					available = false
+					break
+				}
+			}
+			if !available {
+				continue
+			}
+
+			found = append(found, models.AvailableSlot{
+				ArenaID:     arena.ArenaID,
+				ArenaName:   arena.Name,
+				StadiumID:   arena.StadiumID,
+				StadiumName: arena.StadiumName,
+				Location:    arena.Location,
+				SportType:   arena.SportType,
+				Price:       arena.Price * float64(slotsNeeded),
+				SlotStart:   slotStart,
+				SlotEnd:     slotEnd,
+			})
+		}
+
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return found
+}
+
+func getArenasForSlotSearch(params models.SlotSearchParams) ([]models.ArenaWithLocation, error) {
+	query := `
+		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.SportTypeId, a.Description, a.Capacity, a.SlotDuration, a.Price, a.IsPublished, a.CreatedAt,
+		       s.Name AS StadiumName, s.Location
+		FROM Arenas a
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+		  AND (@p1 = '' OR s.Location LIKE '%' + @p1 + '%')
+		  AND (@p2 = '' OR a.SportTypeId IN (SELECT SportTypeId FROM dbo.MatchSportTypes(@p2)))
+		  AND (@p3 = 0 OR a.StadiumId = @p3)
+		  AND (@p4 = 0 OR a.ArenaId = @p4)
+	`
+
+	rows, err := config.DB.Query(query, params.Location, params.SportType, params.StadiumID, params.ArenaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arenas []models.ArenaWithLocation
+	for rows.Next() {
+		var arena models.ArenaWithLocation
+		err := rows.Scan(
+			&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID, &arena.Description,
+			&arena.Capacity, &arena.SlotDuration, &arena.Price, &arena.IsPublished, &arena.CreatedAt,
+			&arena.StadiumName, &arena.Location,
+		)
+		if err != nil {
+			return nil, err
+		}
+		arenas = append(arenas, arena)
+	}
+
+	return arenas, nil
+}
+
+func getBookingsForSlotSearch(params models.SlotSearchParams) (map[int][]models.Booking, error) {
+	query := `
+		SELECT b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.CreatedAt
+		FROM Bookings b
+		INNER JOIN Arenas a ON b.ArenaId = a.ArenaId
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE (@p1 = '' OR s.Location LIKE '%' + @p1 + '%')
+		  AND (@p2 = '' OR a.SportTypeId IN (SELECT SportTypeId FROM dbo.MatchSportTypes(@p2)))
+		  AND (@p3 = 0 OR a.StadiumId = @p3)
+		  AND (@p4 = 0 OR a.ArenaId = @p4)
+		  AND b.Status IN ('Pending', 'Confirmed')
+		  AND b.SlotStart <= @p6 AND b.SlotEnd >= @p5
+	`
+
+	rows, err := config.DB.Query(query, params.Location, params.SportType, params.StadiumID, params.ArenaID, params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := make(map[int][]models.Booking)
+	for rows.Next() {
+		var booking models.Booking
+		err := rows.Scan(&booking.BookingID, &booking.UserID, &booking.ArenaID, &booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		bookings[booking.ArenaID] = append(bookings[booking.ArenaID], booking)
+	}
+
+	return bookings, nil
+}
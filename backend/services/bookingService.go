@@ -1,22 +1,55 @@
 package services
 
 import (
+	"BookMyArena/backend/authz"
 	"BookMyArena/backend/config"
 	"BookMyArena/backend/models"
+	"database/sql"
 	"errors"
+	"strings"
 	"time"
 )
 
 func CreateBooking(userID int, req models.CreateBookingRequest) (*models.Booking, error) {
-	// Verify arena exists
+	return createBooking(userID, req.ArenaID, req.SlotStart, req.SlotEnd, "Pending", "")
+}
+
+// CreateWalkInBooking lets front desk staff book a slot for a customer at the counter. It is confirmed immediately.
+func CreateWalkInBooking(staffID int, req models.WalkInBookingRequest) (*models.Booking, error) {
+	if strings.TrimSpace(req.CustomerName) == "" {
+		return nil, errors.New("customer name is required")
+	}
+
 	arena, err := GetArenaByID(req.ArenaID)
 	if err != nil {
 		return nil, errors.New("arena not found")
 	}
-	_ = arena // Use arena if needed for validation
+
+	if !AuthorizeStadium(staffID, arena.StadiumID, authz.BookingWalkIn) {
+		return nil, errors.New("unauthorized: you can't create walk-in bookings for this arena")
+	}
+
+	return createBooking(staffID, req.ArenaID, req.SlotStart, req.SlotEnd, "Confirmed", strings.TrimSpace(req.CustomerName))
+}
+
+func createBooking(userID, arenaID int, slotStart, slotEnd time.Time, status, walkInName string) (*models.Booking, error) {
+	// Verify arena exists
+	arena, err := GetArenaByID(arenaID)
+	if err != nil {
+		return nil, errors.New("arena not found")
+	}
+
+	// Unpublished listings can't take new bookings
+	stadium, err := GetStadiumByID(arena.StadiumID)
+	if err != nil {
+		return nil, err
+	}
+	if !arena.IsPublished || !IsStadiumListed(stadium) {
+		return nil, errors.New("arena is not available for booking")
+	}
 
 	// Check slot availability
-	available, err := CheckSlotAvailability(req.ArenaID, req.SlotStart, req.SlotEnd)
+	available, err := CheckSlotAvailability(arenaID, slotStart, slotEnd)
 	if err != nil {
 		return nil, err
 	}
@@ -25,33 +58,27 @@ func CreateBooking(userID int, req models.CreateBookingRequest) (*models.Booking
 	}
 
 	// Validate slot times
-	if req.SlotEnd.Before(req.SlotStart) || req.SlotEnd.Equal(req.SlotStart) {
+	if slotEnd.Before(slotStart) || slotEnd.Equal(slotStart) {
 		return nil, errors.New("invalid slot times")
 	}
 
 	// Create booking
 	result := config.DB.QueryRow(
-		"INSERT INTO Bookings (UserId, ArenaId, SlotStart, SlotEnd, Status) OUTPUT INSERTED.BookingId, INSERTED.UserId, INSERTED.ArenaId, INSERTED.SlotStart, INSERTED.SlotEnd, INSERTED.Status, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4, @p5)",
-		userID, req.ArenaID, req.SlotStart, req.SlotEnd, "Pending",
+		"INSERT INTO Bookings (UserId, ArenaId, SlotStart, SlotEnd, Status, WalkInName) OUTPUT INSERTED.BookingId, INSERTED.UserId, INSERTED.ArenaId, INSERTED.SlotStart, INSERTED.SlotEnd, INSERTED.Status, INSERTED.WalkInName, INSERTED.CheckedInAt, INSERTED.CancellationReason, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4, @p5, @p6)",
+		userID, arenaID, slotStart, slotEnd, status, walkInName,
 	)
 
-	booking := &models.Booking{}
-	err = result.Scan(&booking.BookingID, &booking.UserID, &booking.ArenaID, &booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.CreatedAt)
-	if err != nil {
-		return nil, err
-	}
-
-	return booking, nil
+	return scanBooking(result)
 }
 
 func GetBookingsByUser(userID int) ([]models.BookingWithDetails, error) {
 	query := `
-		SELECT b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.CreatedAt,
+		SELECT b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.WalkInName, b.CheckedInAt, b.CancellationReason, b.CreatedAt,
 		       a.Name AS ArenaName, s.Name AS StadiumName, s.Location, a.SportType, a.Price
 		FROM Bookings b
 		INNER JOIN Arenas a ON b.ArenaId = a.ArenaId
 		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
-		WHERE b.UserId = @p1
+		WHERE b.UserId = @p1 AND b.WalkInName = ''
 		ORDER BY b.SlotStart DESC
 	`
 
@@ -61,22 +88,7 @@ func GetBookingsByUser(userID int) ([]models.BookingWithDetails, error) {
 	}
 	defer rows.Close()
 
-	var bookings []models.BookingWithDetails
-	for rows.Next() {
-		var booking models.BookingWithDetails
-		err := rows.Scan(
-			&booking.BookingID, &booking.UserID, &booking.ArenaID,
-			&booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.CreatedAt,
-			&booking.ArenaName, &booking.StadiumName, &booking.Location,
-			&booking.SportType, &booking.Price,
-		)
-		if err != nil {
-			return nil, err
-		}
-		bookings = append(bookings, booking)
-	}
-
-	return bookings, nil
+	return scanBookingsWithDetails(rows)
 }
 
 func GetBookingsByArena(arenaID int) ([]models.Booking, error) {
@@ -103,12 +115,12 @@ func GetBookingsByArena(arenaID int) ([]models.Booking, error) {
 }
 
 func GetBookingByID(bookingID int) (*models.Booking, error) {
-	booking := &models.Booking{}
-	err := config.DB.QueryRow(
-		"SELECT BookingId, UserId, ArenaId, SlotStart, SlotEnd, Status, CreatedAt FROM Bookings WHERE BookingId = @p1",
+	row := config.DB.QueryRow(
+		"SELECT BookingId, UserId, ArenaId, SlotStart, SlotEnd, Status, WalkInName, CheckedInAt, CancellationReason, CreatedAt FROM Bookings WHERE BookingId = @p1",
 		bookingID,
-	).Scan(&booking.BookingID, &booking.UserID, &booking.ArenaID, &booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.CreatedAt)
+	)
 
+	booking, err := scanBooking(row)
 	if err != nil {
 		return nil, errors.New("booking not found")
 	}
@@ -116,7 +128,9 @@ func GetBookingByID(bookingID int) (*models.Booking, error) {
 	return booking, nil
 }
 
-func CancelBooking(bookingID, userID int) error {
+// CancelBooking cancels a booking for the user who made it. audit says who acted and from where;
+// the entry is written in the same transaction as the change.
+func CancelBooking(deps *Deps, bookingID, userID int, audit models.AuditEvent) error {
 	// Verify booking belongs to user
 	booking, err := GetBookingByID(bookingID)
 	if err != nil {
@@ -136,17 +150,39 @@ func CancelBooking(bookingID, userID int) error {
 		return errors.New("cannot cancel past bookings")
 	}
 
-	_, err = config.DB.Exec(
+	arena, err := GetArenaByID(booking.ArenaID)
+	if err != nil {
+		return err
+	}
+
+	after := *booking
+	after.Status = "Cancelled"
+	audit.Action = AuditActionBookingCancelled
+	err = commitBookingChange(audit, arena.StadiumID, booking, &after,
 		"UPDATE Bookings SET Status = 'Cancelled' WHERE BookingId = @p1",
 		bookingID,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// The freed slot may be what someone's saved search is waiting for
+	notifySavedSearchesAsync(deps, booking.ArenaID)
+
+	return nil
 }
 
-func UpdateBookingStatus(bookingID int, status string, ownerID int) error {
+// UpdateBookingStatus confirms or cancels a booking on behalf of the stadium. Like CancelBooking,
+// the audit entry commits with the change.
+func UpdateBookingStatus(deps *Deps, bookingID int, status string, actorID int, audit models.AuditEvent) error {
 	// Verify status
-	if status != "Confirmed" && status != "Cancelled" {
+	var permission authz.Permission
+	switch status {
+	case "Confirmed":
+		permission = authz.BookingConfirm
+	case "Cancelled":
+		permission = authz.BookingCancel
+	default:
 		return errors.New("invalid status")
 	}
 
@@ -156,33 +192,94 @@ func UpdateBookingStatus(bookingID int, status string, ownerID int) error {
 		return err
 	}
 
-	// Verify owner owns the arena (arenaService functions are accessible in same package)
+	// Verify the actor owns or staffs the arena's stadium (arenaService functions are accessible in same package)
 	arena, err := GetArenaByID(booking.ArenaID)
 	if err != nil {
 		return err
 	}
 
-	// Get stadium to verify ownership (stadiumService functions are accessible in same package)
-	stadium, err := GetStadiumByID(arena.StadiumID)
+	if !AuthorizeStadium(actorID, arena.StadiumID, permission) {
+		return errors.New("unauthorized: you can't manage bookings for this arena")
+	}
+
+	after := *booking
+	after.Status = status
+	audit.Action = AuditActionBookingStatus
+	err = commitBookingChange(audit, arena.StadiumID, booking, &after,
+		"UPDATE Bookings SET Status = @p1 WHERE BookingId = @p2",
+		status, bookingID,
+	)
 	if err != nil {
 		return err
 	}
 
-	if stadium.OwnerID != ownerID {
-		return errors.New("unauthorized: you don't own this arena")
+	if status == "Cancelled" && booking.Status != "Cancelled" {
+		notifySavedSearchesAsync(deps, booking.ArenaID)
 	}
 
-	_, err = config.DB.Exec(
-		"UPDATE Bookings SET Status = @p1 WHERE BookingId = @p2",
-		status, bookingID,
+	return nil
+}
+
+func CheckInBooking(bookingID, actorID int, audit models.AuditEvent) error {
+	booking, err := GetBookingByID(bookingID)
+	if err != nil {
+		return err
+	}
+
+	arena, err := GetArenaByID(booking.ArenaID)
+	if err != nil {
+		return err
+	}
+
+	if !AuthorizeStadium(actorID, arena.StadiumID, authz.BookingCheckIn) {
+		return errors.New("unauthorized: you can't check in bookings for this arena")
+	}
+
+	if booking.Status != "Confirmed" {
+		return errors.New("only confirmed bookings can be checked in")
+	}
+	if booking.CheckedInAt != nil {
+		return errors.New("booking is already checked in")
+	}
+
+	now := time.Now()
+	after := *booking
+	after.CheckedInAt = &now
+	audit.Action = AuditActionBookingCheckIn
+	return commitBookingChange(audit, arena.StadiumID, booking, &after,
+		"UPDATE Bookings SET CheckedInAt = @p1 WHERE BookingId = @p2",
+		now, bookingID,
 	)
+}
+
+// commitBookingChange runs an update to a booking and writes its audit entry in one transaction,
+// so a change is never made without a record of it
+func commitBookingChange(audit models.AuditEvent, stadiumID int, before, after *models.Booking, query string, args ...interface{}) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return err
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+
+	audit.EntityType = AuditEntityBooking
+	audit.EntityID = before.BookingID
+	audit.StadiumID = stadiumID
+	audit.Before = before
+	audit.After = after
+	if err := recordAuditEntry(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func GetOwnerBookings(ownerID int) ([]models.BookingWithDetails, error) {
 	query := `
-		SELECT b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.CreatedAt,
+		SELECT b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.WalkInName, b.CheckedInAt, b.CancellationReason, b.CreatedAt,
 		       a.Name AS ArenaName, s.Name AS StadiumName, s.Location, a.SportType, a.Price
 		FROM Bookings b
 		INNER JOIN Arenas a ON b.ArenaId = a.ArenaId
@@ -197,21 +294,61 @@ func GetOwnerBookings(ownerID int) ([]models.BookingWithDetails, error) {
 	}
 	defer rows.Close()
 
+	return scanBookingsWithDetails(rows)
+}
+
+func GetStadiumBookings(stadiumID int) ([]models.BookingWithDetails, error) {
+	query := `
+		SELECT b.BookingId, b.UserId, b.ArenaId, b.SlotStart, b.SlotEnd, b.Status, b.WalkInName, b.CheckedInAt, b.CancellationReason, b.CreatedAt,
+		       a.Name AS ArenaName, s.Name AS StadiumName, s.Location, a.SportType, a.Price
+		FROM Bookings b
+		INNER JOIN Arenas a ON b.ArenaId = a.ArenaId
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE s.StadiumId = @p1
+		ORDER BY b.SlotStart DESC
+	`
+
+	rows, err := config.DB.Query(query, stadiumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBookingsWithDetails(rows)
+}
+
+func scanBooking(row rowScanner) (*models.Booking, error) {
+	booking := &models.Booking{}
+	var checkedInAt sql.NullTime
+	err := row.Scan(&booking.BookingID, &booking.UserID, &booking.ArenaID, &booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.WalkInName, &checkedInAt, &booking.CancellationReason, &booking.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if checkedInAt.Valid {
+		booking.CheckedInAt = &checkedInAt.Time
+	}
+	return booking, nil
+}
+
+func scanBookingsWithDetails(rows *sql.Rows) ([]models.BookingWithDetails, error) {
 	var bookings []models.BookingWithDetails
 	for rows.Next() {
 		var booking models.BookingWithDetails
+		var checkedInAt sql.NullTime
 		err := rows.Scan(
 			&booking.BookingID, &booking.UserID, &booking.ArenaID,
-			&booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.CreatedAt,
+			&booking.SlotStart, &booking.SlotEnd, &booking.Status, &booking.WalkInName, &checkedInAt, &booking.CancellationReason, &booking.CreatedAt,
 			&booking.ArenaName, &booking.StadiumName, &booking.Location,
 			&booking.SportType, &booking.Price,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if checkedInAt.Valid {
+			booking.CheckedInAt = &checkedInAt.Time
+		}
 		bookings = append(bookings, booking)
 	}
 
 	return bookings, nil
 }
-
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"BookMyArena/backend/breached"
+	"BookMyArena/backend/config"
+	"strings"
+	"time"
+)
+
+// Deps holds the settings the services read while handling requests. main builds it once with
+// NewDeps and hands it to the routes; services that depend on a setting take it as their first
+// argument. It is never modified afterwards, so requests can share it.
+type Deps struct {
+	baseURL           string // public origin links in emails point to, without a trailing slash
+	sessions          config.SessionSettings
+	slots             config.SlotSettings
+	passwordPolicy    PasswordPolicy
+	breachedPasswords breached.List // nil unless a breach list is configured
+
+	// cacheTTL bounds how stale a cached session or user can get when an invalidation is missed,
+	// e.g. a change made directly in the database
+	cacheTTL time.Duration
+}
+
+// NewDeps prepares the settings for the services, loading the breached password list if one is
+// configured. The settings have been validated already.
+func NewDeps(settings *config.Settings) (*Deps, error) {
+	policy, breachedPasswords, err := newPasswordPolicy(settings.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Deps{
+		baseURL:           strings.TrimRight(settings.App.BaseURL, "/"),
+		sessions:          settings.Session,
+		slots:             settings.Slots,
+		passwordPolicy:    policy,
+		breachedPasswords: breachedPasswords,
+		cacheTTL:          settings.Cache.TTL,
+	}, nil
+}
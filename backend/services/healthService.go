@@ -0,0 +1,149 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/jobs"
+	"BookMyArena/backend/models"
+	"context"
+	"errors"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Version names the build. Release builds set it with
+// -ldflags "-X BookMyArena/backend/services.Version=1.4.0"; otherwise the module version is used.
+var Version = "dev"
+
+var startedAt = time.Now()
+
+// Load balancers give up on a slow probe, so readiness must answer well within their timeout
+const readinessTimeout = 3 * time.Second
+
+// CheckReadiness reports whether this instance can serve traffic: the database answers, its schema
+// is current and the background jobs are running. Errors are kept generic since /readyz is public;
+// /debug/info has the details.
+func CheckReadiness(ctx context.Context) models.ReadinessReport {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	report := models.ReadinessReport{Ready: true}
+	check := func(name string, err error, message string) {
+		result := models.HealthCheck{Name: name, OK: err == nil}
+		if err != nil {
+			report.Ready = false
+			result.Error = message
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	dbErr := config.PingDB(ctx)
+	check("database", dbErr, "database unreachable")
+
+	if dbErr != nil {
+		check("schema", dbErr, "not checked, database unreachable")
+	} else {
+		err := config.CheckSchema(ctx)
+		message := "schema version could not be read"
+		if errors.Is(err, config.ErrSchemaOutdated) {
+			message = err.Error()
+		}
+		check("schema", err, message)
+	}
+
+	err := jobs.Healthy()
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	check("jobs", err, message)
+
+	return report
+}
+
+// GetDebugInfo collects build, runtime, database pool and job details for admins
+func GetDebugInfo(ctx context.Context) *models.DebugInfo {
+	info := &models.DebugInfo{
+		Version:       Version,
+		GoVersion:     runtime.Version(),
+		StartedAt:     startedAt,
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		Jobs:          []models.JobDebugInfo{},
+		AuditFailures: AuditFailures(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		info.Revision = buildRevision(build)
+	}
+
+	stats := config.DB.Stats()
+	info.Database = models.DatabaseDebugInfo{
+		ExpectedSchemaVersion: config.SchemaVersion,
+		MaxOpenConnections:    stats.MaxOpenConnections,
+		OpenConnections:       stats.OpenConnections,
+		InUse:                 stats.InUse,
+		Idle:                  stats.Idle,
+		WaitCount:             stats.WaitCount,
+		WaitDurationMs:        stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:         stats.MaxIdleClosed,
+		MaxIdleTimeClosed:     stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:     stats.MaxLifetimeClosed,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	if err := config.PingDB(ctx); err != nil {
+		info.Database.Error = err.Error()
+	} else {
+		info.Database.Available = true
+		version, err := config.AppliedSchemaVersion(ctx)
+		if err != nil {
+			log.Println("Error reading schema version:", err)
+			info.Database.Error = err.Error()
+		}
+		info.Database.SchemaVersion = version
+	}
+
+	for _, status := range jobs.Statuses() {
+		job := models.JobDebugInfo{
+			Name:     status.Name,
+			Interval: status.Interval.String(),
+			Running:  status.Running,
+			Runs:     status.Runs,
+		}
+		if !status.LastStarted.IsZero() {
+			lastStarted := status.LastStarted
+			job.LastStarted = &lastStarted
+		}
+		if !status.LastFinished.IsZero() {
+			lastFinished := status.LastFinished
+			job.LastFinished = &lastFinished
+			job.LastDurationMs = status.LastFinished.Sub(status.LastStarted).Milliseconds()
+		}
+		info.Jobs = append(info.Jobs, job)
+	}
+
+	return info
+}
+
+// buildRevision is the VCS commit the binary was built from, marked if the tree had local changes
+func buildRevision(build *debug.BuildInfo) string {
+	var revision string
+	modified := false
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
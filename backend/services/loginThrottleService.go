@@ -0,0 +1,287 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/mail"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LoginThrottleAccount = "account"
+	LoginThrottleIP      = "ip"
+
+	NotificationTypeAccountLocked = "AccountLocked"
+
+	// A failure streak is forgotten after this long without another failure
+	loginFailureWindow = time.Hour
+)
+
+// loginThrottlePolicy: the first freeFailures mistakes cost nothing, then each one doubles the wait
+// (from one second, capped at maxBackoff) until lockoutAt failures, which blocks for lockoutDuration
+type loginThrottlePolicy struct {
+	freeFailures    int
+	maxBackoff      time.Duration
+	lockoutAt       int
+	lockoutDuration time.Duration
+}
+
+// IPs get more room than accounts since many users can share one address
+var loginThrottlePolicies = map[string]loginThrottlePolicy{
+	LoginThrottleAccount: {freeFailures: 3, maxBackoff: 5 * time.Minute, lockoutAt: 10, lockoutDuration: 15 * time.Minute},
+	LoginThrottleIP:      {freeFailures: 10, maxBackoff: 5 * time.Minute, lockoutAt: 50, lockoutDuration: 15 * time.Minute},
+}
+
+func (p loginThrottlePolicy) delay(failures int) time.Duration {
+	if failures >= p.lockoutAt {
+		return p.lockoutDuration
+	}
+	if failures <= p.freeFailures {
+		return 0
+	}
+
+	backoff := time.Second << uint(failures-p.freeFailures-1)
+	if backoff > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return backoff
+}
+
+// LoginRetryAfter reports how long logins for this email from this address are blocked, or 0.
+// Emails without an account are throttled just the same, so the answer says nothing about whether one exists.
+func LoginRetryAfter(email, ipAddress string) (time.Duration, error) {
+	var blockedUntil sql.NullTime
+	err := config.DB.QueryRow(`
+		SELECT MAX(BlockedUntil) FROM LoginThrottles
+		WHERE (Scope = @p1 AND ThrottleKey = @p2) OR (Scope = @p3 AND ThrottleKey = @p4)
+	`, LoginThrottleAccount, loginThrottleKey(email), LoginThrottleIP, ipAddress).Scan(&blockedUntil)
+	if err != nil {
+		return 0, err
+	}
+
+	if !blockedUntil.Valid {
+		return 0, nil
+	}
+	if wait := time.Until(blockedUntil.Time); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// recordLoginFailure counts a failed login against the account and the address
+func recordLoginFailure(deps *Deps, email, ipAddress string, user *models.User) {
+	if err := addLoginFailure(deps, LoginThrottleAccount, loginThrottleKey(email), user); err != nil {
+		log.Println("Error recording failed login:", err)
+	}
+	if ipAddress != "" {
+		if err := addLoginFailure(deps, LoginThrottleIP, ipAddress, nil); err != nil {
+			log.Println("Error recording failed login:", err)
+		}
+	}
+}
+
+// recordLoginSuccess clears the account's failure streak. The address keeps its count so an attacker
+// can't reset it by logging in to an account of their own.
+func recordLoginSuccess(email string) {
+	_, err := config.DB.Exec(
+		"DELETE FROM LoginThrottles WHERE Scope = @p1 AND ThrottleKey = @p2",
+		LoginThrottleAccount, loginThrottleKey(email),
+	)
+	if err != nil {
+		log.Println("Error clearing failed logins:", err)
+	}
+}
+
+func addLoginFailure(deps *Deps, scope, key string, user *models.User) error {
+	now := time.Now()
+
+	var failures int
+	err := config.DB.QueryRow(`
+		MERGE LoginThrottles WITH (HOLDLOCK) AS target
+		USING (SELECT @p1 AS Scope, @p2 AS ThrottleKey) AS source
+		ON target.Scope = source.Scope AND target.ThrottleKey = source.ThrottleKey
+		WHEN MATCHED THEN UPDATE SET
+			Failures = CASE WHEN target.LastFailureAt < @p4 THEN 1 ELSE target.Failures + 1 END,
+			LastFailureAt = @p3
+		WHEN NOT MATCHED THEN INSERT (Scope, ThrottleKey, Failures, LastFailureAt) VALUES (@p1, @p2, 1, @p3)
+		OUTPUT INSERTED.Failures;
+	`, scope, key, now, now.Add(-loginFailureWindow)).Scan(&failures)
+	if err != nil {
+		return err
+	}
+
+	policy := loginThrottlePolicies[scope]
+	delay := policy.delay(failures)
+	if delay == 0 {
+		return nil
+	}
+
+	blockedUntil := now.Add(delay)
+	_, err = config.DB.Exec(
+		"UPDATE LoginThrottles SET BlockedUntil = @p1 WHERE Scope = @p2 AND ThrottleKey = @p3",
+		blockedUntil, scope, key,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Only the failure that crosses the threshold starts a lockout; later ones just extend the block
+	if failures != policy.lockoutAt {
+		return nil
+	}
+
+	var userID sql.NullInt64
+	if user != nil {
+		userID = sql.NullInt64{Int64: int64(user.UserID), Valid: true}
+	}
+	_, err = config.DB.Exec(
+		"INSERT INTO AccountLockouts (Scope, ThrottleKey, UserId, Failures, LockedUntil) VALUES (@p1, @p2, @p3, @p4, @p5)",
+		scope, key, userID, failures, blockedUntil,
+	)
+	if err != nil {
+		return err
+	}
+
+	if user != nil {
+		notifyAccountLocked(deps, user, blockedUntil)
+	}
+	return nil
+}
+
+// notifyAccountLocked tells the owner both in the app and by email, since they may not be able to sign in
+func notifyAccountLocked(deps *Deps, user *models.User, lockedUntil time.Time) {
+	message := fmt.Sprintf("Sign-in to your account was blocked until %s after too many failed attempts", lockedUntil.Format("15:04 MST"))
+	if err := CreateNotification(user.UserID, NotificationTypeAccountLocked, message, map[string]time.Time{"lockedUntil": lockedUntil}); err != nil {
+		log.Println("Error creating lockout notification:", err)
+	}
+
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Sign-in to your BookMyArena account was blocked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThere were too many failed attempts to sign in to your BookMyArena account, so sign-in is blocked until %s.\n\nIf this wasn't you, someone may be guessing your password. Consider changing it at %s\n",
+			user.FullName, lockedUntil.Format("2006-01-02 15:04 MST"), deps.appURL("/frontend/pages/forgot-password.html"),
+		),
+	})
+	if err != nil {
+		log.Println("Error sending lockout email:", err)
+	}
+}
+
+// GetAccountLockouts lists lockouts, newest first. activeOnly keeps just the ones still in force.
+func GetAccountLockouts(activeOnly bool, limit int) ([]models.AccountLockout, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	now := time.Now()
+	rows, err := config.DB.Query(`
+		SELECT TOP (@p1) LockoutId, Scope, ThrottleKey, UserId, Failures, LockedAt, LockedUntil, ClearedAt, ClearedBy
+		FROM AccountLockouts
+		WHERE @p2 = 0 OR (ClearedAt IS NULL AND LockedUntil > @p3)
+		ORDER BY LockedAt DESC
+	`, limit, activeOnly, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lockouts []models.AccountLockout
+	for rows.Next() {
+		lockout, err := scanAccountLockout(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockout.Active = lockout.ClearedAt == nil && lockout.LockedUntil.After(now)
+		lockouts = append(lockouts, *lockout)
+	}
+
+	return lockouts, rows.Err()
+}
+
+// ClearAccountLockout lifts a lockout early and forgets the failure streak behind it
+func ClearAccountLockout(adminID, lockoutID int) (*models.AccountLockout, error) {
+	lockout, err := scanAccountLockout(config.DB.QueryRow(`
+		UPDATE AccountLockouts SET ClearedAt = @p1, ClearedBy = @p2
+		OUTPUT INSERTED.LockoutId, INSERTED.Scope, INSERTED.ThrottleKey, INSERTED.UserId, INSERTED.Failures,
+			INSERTED.LockedAt, INSERTED.LockedUntil, INSERTED.ClearedAt, INSERTED.ClearedBy
+		WHERE LockoutId = @p3 AND ClearedAt IS NULL
+	`, time.Now(), adminID, lockoutID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("lockout not found or already cleared")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = config.DB.Exec(
+		"DELETE FROM LoginThrottles WHERE Scope = @p1 AND ThrottleKey = @p2",
+		lockout.Scope, lockout.ThrottleKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return lockout, nil
+}
+
+// CleanExpiredLoginThrottles drops failure streaks that have gone quiet
+func CleanExpiredLoginThrottles() {
+	config.DB.Exec(
+		"DELETE FROM LoginThrottles WHERE LastFailureAt < @p1 AND (BlockedUntil IS NULL OR BlockedUntil < GETDATE())",
+		time.Now().Add(-loginFailureWindow),
+	)
+}
+
+func scanAccountLockout(row rowScanner) (*models.AccountLockout, error) {
+	lockout := &models.AccountLockout{}
+	var userID, clearedBy sql.NullInt64
+	var clearedAt sql.NullTime
+	err := row.Scan(&lockout.LockoutID, &lockout.Scope, &lockout.ThrottleKey, &userID, &lockout.Failures,
+		&lockout.LockedAt, &lockout.LockedUntil, &clearedAt, &clearedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID.Valid {
+		id := int(userID.Int64)
+		lockout.UserID = &id
+	}
+	if clearedAt.Valid {
+		lockout.ClearedAt = &clearedAt.Time
+	}
+	if clearedBy.Valid {
+		id := int(clearedBy.Int64)
+		lockout.ClearedBy = &id
+	}
+	return lockout, nil
+}
+
+func loginThrottleKey(email string) string {
+	return truncate(strings.ToLower(strings.TrimSpace(email)), 255)
+}
+
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// compareDummyPassword spends the same bcrypt time as a real check, so a missing account or one
+// without a password answers no faster than a wrong password
+func compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		hash, err := HashPassword("not-a-real-password")
+		if err != nil {
+			log.Println("Error creating dummy password hash:", err)
+			return
+		}
+		dummyPasswordHash = hash
+	})
+	VerifyPassword(dummyPasswordHash, password)
+}
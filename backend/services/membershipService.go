@@ -0,0 +1,198 @@
+package services
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	NotificationTypeStaffInvite            = "StaffInvite"
+	NotificationTypeStaffTwoFactorRequired = "StaffTwoFactorRequired"
+)
+
+func InviteStadiumMember(stadiumID, invitedBy int, req models.InviteStadiumMemberRequest) (*models.StadiumMember, error) {
+	if !authz.IsMemberRole(req.Role) {
+		return nil, errors.New("invalid role. Must be 'Manager', 'FrontDesk' or 'Viewer'")
+	}
+
+	stadium, err := GetStadiumByID(stadiumID)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID int
+	err = config.DB.QueryRow("SELECT UserId FROM Users WHERE Email = @p1", req.Email).Scan(&userID)
+	if err != nil {
+		return nil, errors.New("no account exists with that email")
+	}
+	if userID == stadium.OwnerID {
+		return nil, errors.New("the stadium owner cannot be invited as staff")
+	}
+
+	var count int
+	err = config.DB.QueryRow(
+		"SELECT COUNT(*) FROM StadiumMembers WHERE StadiumId = @p1 AND UserId = @p2",
+		stadiumID, userID,
+	).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("user is already a member or has a pending invite")
+	}
+
+	result := config.DB.QueryRow(
+		"INSERT INTO StadiumMembers (StadiumId, UserId, Role, InvitedBy) OUTPUT INSERTED.MemberId, INSERTED.StadiumId, INSERTED.UserId, INSERTED.Role, INSERTED.Status, INSERTED.InvitedBy, INSERTED.CreatedAt, INSERTED.AcceptedAt VALUES (@p1, @p2, @p3, @p4)",
+		stadiumID, userID, req.Role, invitedBy,
+	)
+
+	member, err := scanStadiumMember(result)
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("You have been invited to join %s as %s", stadium.Name, req.Role)
+	CreateNotification(userID, NotificationTypeStaffInvite, message, map[string]int{
+		"memberId":  member.MemberID,
+		"stadiumId": stadiumID,
+	})
+
+	return member, nil
+}
+
+func GetStadiumMembers(stadiumID int) ([]models.StadiumMemberWithDetails, error) {
+	query := `
+		SELECT m.MemberId, m.StadiumId, m.UserId, m.Role, m.Status, m.InvitedBy, m.CreatedAt, m.AcceptedAt,
+		       u.FullName, u.Email, s.Name AS StadiumName
+		FROM StadiumMembers m
+		INNER JOIN Users u ON m.UserId = u.UserId
+		INNER JOIN Stadiums s ON m.StadiumId = s.StadiumId
+		WHERE m.StadiumId = @p1
+		ORDER BY m.CreatedAt
+	`
+	return queryStadiumMembers(query, stadiumID)
+}
+
+func GetMembershipsByUser(userID int) ([]models.StadiumMemberWithDetails, error) {
+	query := `
+		SELECT m.MemberId, m.StadiumId, m.UserId, m.Role, m.Status, m.InvitedBy, m.CreatedAt, m.AcceptedAt,
+		       u.FullName, u.Email, s.Name AS StadiumName
+		FROM StadiumMembers m
+		INNER JOIN Users u ON m.UserId = u.UserId
+		INNER JOIN Stadiums s ON m.StadiumId = s.StadiumId
+		WHERE m.UserId = @p1
+		ORDER BY m.CreatedAt DESC
+	`
+	return queryStadiumMembers(query, userID)
+}
+
+func GetStadiumMemberByID(memberID int) (*models.StadiumMember, error) {
+	row := config.DB.QueryRow(
+		"SELECT MemberId, StadiumId, UserId, Role, Status, InvitedBy, CreatedAt, AcceptedAt FROM StadiumMembers WHERE MemberId = @p1",
+		memberID,
+	)
+
+	member, err := scanStadiumMember(row)
+	if err != nil {
+		return nil, errors.New("membership not found")
+	}
+
+	return member, nil
+}
+
+func AcceptStadiumInvite(memberID, userID int) error {
+	member, err := GetStadiumMemberByID(memberID)
+	if err != nil {
+		return err
+	}
+	if member.UserID != userID {
+		return errors.New("membership not found")
+	}
+	if member.Status != "Invited" {
+		return errors.New("invite has already been accepted")
+	}
+
+	_, err = config.DB.Exec(
+		"UPDATE StadiumMembers SET Status = 'Active', AcceptedAt = @p1 WHERE MemberId = @p2",
+		time.Now(), memberID,
+	)
+	return err
+}
+
+func UpdateStadiumMemberRole(stadiumID, memberID int, role string) error {
+	if !authz.IsMemberRole(role) {
+		return errors.New("invalid role. Must be 'Manager', 'FrontDesk' or 'Viewer'")
+	}
+
+	result, err := config.DB.Exec(
+		"UPDATE StadiumMembers SET Role = @p1 WHERE MemberId = @p2 AND StadiumId = @p3",
+		role, memberID, stadiumID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.New("membership not found")
+	}
+
+	return nil
+}
+
+// RemoveStadiumMember deletes a membership; used both for removing staff and for declining or leaving
+func RemoveStadiumMember(memberID int) error {
+	result, err := config.DB.Exec("DELETE FROM StadiumMembers WHERE MemberId = @p1", memberID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.New("membership not found")
+	}
+
+	return nil
+}
+
+func queryStadiumMembers(query string, args ...interface{}) ([]models.StadiumMemberWithDetails, error) {
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.StadiumMemberWithDetails
+	for rows.Next() {
+		var member models.StadiumMemberWithDetails
+		var acceptedAt sql.NullTime
+		err := rows.Scan(
+			&member.MemberID, &member.StadiumID, &member.UserID, &member.Role, &member.Status,
+			&member.InvitedBy, &member.CreatedAt, &acceptedAt,
+			&member.FullName, &member.Email, &member.StadiumName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if acceptedAt.Valid {
+			member.AcceptedAt = &acceptedAt.Time
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func scanStadiumMember(row rowScanner) (*models.StadiumMember, error) {
+	member := &models.StadiumMember{}
+	var acceptedAt sql.NullTime
+	err := row.Scan(&member.MemberID, &member.StadiumID, &member.UserID, &member.Role, &member.Status, &member.InvitedBy, &member.CreatedAt, &acceptedAt)
+	if err != nil {
+		return nil, err
+	}
+	if acceptedAt.Valid {
+		member.AcceptedAt = &acceptedAt.Time
+	}
+	return member, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	NotificationTypeSlotOpened = "SlotOpened"
+
+	maxNotificationsListed = 100
+)
+
+func CreateNotification(userID int, notificationType, message string, data interface{}) error {
+	var payload sql.NullString
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		payload = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	_, err := config.DB.Exec(
+		"INSERT INTO Notifications (UserId, Type, Message, Data) VALUES (@p1, @p2, @p3, @p4)",
+		userID, notificationType, message, payload,
+	)
+	return err
+}
+
+func GetNotificationsByUser(userID int, unreadOnly bool) ([]models.Notification, error) {
+	rows, err := config.DB.Query(
+		`SELECT TOP (@p3) NotificationId, UserId, Type, Message, Data, ReadAt, CreatedAt
+		 FROM Notifications
+		 WHERE UserId = @p1 AND (@p2 = 0 OR ReadAt IS NULL)
+		 ORDER BY CreatedAt DESC`,
+		userID, unreadOnly, maxNotificationsListed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var notification models.Notification
+		var data sql.NullString
+		var readAt sql.NullTime
+		err := rows.Scan(&notification.NotificationID, &notification.UserID, &notification.Type, &notification.Message, &data, &readAt, &notification.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if data.Valid {
+			notification.Data = json.RawMessage(data.String)
+		}
+		if readAt.Valid {
+			notification.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func MarkNotificationRead(notificationID, userID int) error {
+	result, err := config.DB.Exec(
+		"UPDATE Notifications SET ReadAt = @p1 WHERE NotificationId = @p2 AND UserId = @p3 AND ReadAt IS NULL",
+		time.Now(), notificationID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// Either it isn't the user's notification or it was already read
+		var count int
+		err = config.DB.QueryRow(
+			"SELECT COUNT(*) FROM Notifications WHERE NotificationId = @p1 AND UserId = @p2",
+			notificationID, userID,
+		).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("notification not found")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/oidc"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// How long the user has to finish signing in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// InitOIDC registers the configured identity providers. Their default callback is on this server.
+func InitOIDC(deps *Deps, settings config.OIDCSettings) {
+	configs := make([]oidc.Config, 0, len(settings.Providers))
+	for _, p := range settings.Providers {
+		redirectURL := p.RedirectURL
+		if redirectURL == "" {
+			redirectURL = deps.appURL("/api/auth/oidc/" + p.Name + "/callback")
+		}
+		configs = append(configs, oidc.Config{
+			Name:         p.Name,
+			DisplayName:  p.DisplayName,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	oidc.Init(configs)
+}
+
+func GetOIDCProviders() []models.OIDCProvider {
+	list := []models.OIDCProvider{}
+	for _, p := range oidc.Providers() {
+		list = append(list, models.OIDCProvider{Name: p.Name, DisplayName: p.DisplayName})
+	}
+	return list
+}
+
+// BeginOIDCLogin starts an authorization code login and returns the provider URL to send the browser
+// to, along with the state value the caller must bind to the browser
+func BeginOIDCLogin(ctx context.Context, providerName string, rememberMe bool) (string, string, error) {
+	provider, ok := oidc.Get(providerName)
+	if !ok {
+		return "", "", errors.New("unknown login provider")
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		log.Printf("OIDC provider %s: %v\n", providerName, err)
+		return "", "", errors.New("login provider is unavailable")
+	}
+
+	_, err = config.DB.Exec(
+		"INSERT INTO OidcLoginStates (StateHash, Provider, Nonce, CodeVerifier, RememberMe, ExpiresAt) VALUES (@p1, @p2, @p3, @p4, @p5, @p6)",
+		hashToken(state), providerName, nonce, verifier, rememberMe, time.Now().Add(oidcLoginTTL),
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin handles the provider's callback: it spends the state, exchanges the code and
+// validates the ID token, then finds, links or creates the local account
+func CompleteOIDCLogin(deps *Deps, ctx context.Context, providerName, state, code string) (*models.OIDCLoginResult, error) {
+	provider, ok := oidc.Get(providerName)
+	if !ok {
+		return nil, errors.New("unknown login provider")
+	}
+
+	var nonce, verifier string
+	var rememberMe bool
+	err := config.DB.QueryRow(
+		"DELETE FROM OidcLoginStates OUTPUT DELETED.Nonce, DELETED.CodeVerifier, DELETED.RememberMe WHERE StateHash = @p1 AND Provider = @p2 AND ExpiresAt > @p3",
+		hashToken(state), providerName, time.Now(),
+	).Scan(&nonce, &verifier, &rememberMe)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("login has expired, please try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		log.Printf("OIDC provider %s: %v\n", providerName, err)
+		return nil, errors.New("could not verify the login with the provider")
+	}
+
+	result, err := userForIdentity(deps, providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+	result.Provider = providerName
+	result.RememberMe = rememberMe
+
+	if result.User.Status == UserStatusSuspended {
+		return nil, errors.New("account is suspended")
+	}
+	if result.User.Status == UserStatusDeleted {
+		return nil, errors.New("account has been deleted")
+	}
+
+	if result.User.TwoFactorEnabled {
+		result.ChallengeToken, err = StartLoginChallenge(result.User.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetUserIdentities lists the providers linked to an account
+func GetUserIdentities(userID int) ([]models.UserIdentity, error) {
+	rows, err := config.DB.Query(
+		"SELECT IdentityId, UserId, Provider, Subject, Email, CreatedAt, LastLoginAt FROM UserIdentities WHERE UserId = @p1 ORDER BY CreatedAt",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []models.UserIdentity
+	for rows.Next() {
+		var identity models.UserIdentity
+		var lastLoginAt sql.NullTime
+		err := rows.Scan(&identity.IdentityID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt, &lastLoginAt)
+		if err != nil {
+			return nil, err
+		}
+		if lastLoginAt.Valid {
+			identity.LastLoginAt = &lastLoginAt.Time
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// CleanExpiredOIDCStates drops logins that were started but never finished
+func CleanExpiredOIDCStates() {
+	config.DB.Exec("DELETE FROM OidcLoginStates WHERE ExpiresAt < GETDATE()")
+}
+
+// userForIdentity resolves the provider's subject to an account. A new identity is linked to an
+// existing account only when both sides have verified the email address; otherwise someone who
+// registered the address first (or a provider that doesn't check it) could take the account over.
+func userForIdentity(deps *Deps, providerName string, claims *oidc.Claims) (*models.OIDCLoginResult, error) {
+	email := strings.TrimSpace(claims.Email)
+
+	var userID int
+	err := config.DB.QueryRow(
+		"SELECT UserId FROM UserIdentities WHERE Provider = @p1 AND Subject = @p2",
+		providerName, claims.Subject,
+	).Scan(&userID)
+	if err == nil {
+		_, err = config.DB.Exec(
+			"UPDATE UserIdentities SET LastLoginAt = @p1, Email = @p2 WHERE Provider = @p3 AND Subject = @p4",
+			time.Now(), email, providerName, claims.Subject,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err := GetUserByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.OIDCLoginResult{User: user}, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if email == "" {
+		return nil, errors.New("the login provider did not share an email address")
+	}
+
+	existing, err := scanUser(config.DB.QueryRow("SELECT "+userColumns+" FROM Users WHERE Email = @p1", email))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if existing != nil {
+		if !bool(claims.EmailVerified) || !existing.EmailVerified {
+			return nil, errors.New("an account with this email already exists, please sign in with your password")
+		}
+		if err := linkIdentity(existing.UserID, providerName, claims.Subject, email); err != nil {
+			return nil, err
+		}
+		return &models.OIDCLoginResult{User: existing, Linked: true}, nil
+	}
+
+	user, err := createUserFromClaims(email, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := linkIdentity(user.UserID, providerName, claims.Subject, email); err != nil {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		if err := SendVerificationEmail(deps, user); err != nil {
+			log.Println("Error sending verification email:", err)
+		}
+	}
+
+	return &models.OIDCLoginResult{User: user, Created: true}, nil
+}
+
+// createUserFromClaims creates a customer account with no usable password. The user can set one
+// later through the forgot-password flow.
+func createUserFromClaims(email string, claims *oidc.Claims) (*models.User, error) {
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName = strings.TrimSpace(claims.GivenName + " " + claims.FamilyName)
+	}
+	if fullName == "" {
+		fullName = strings.SplitN(email, "@", 2)[0]
+	}
+	fullName = truncate(fullName, 100)
+
+	var verifiedAt interface{}
+	if claims.EmailVerified {
+		verifiedAt = time.Now()
+	}
+
+	return scanUser(config.DB.QueryRow(
+		"INSERT INTO Users (FullName, Email, PasswordHash, Role, EmailVerified, EmailVerifiedAt) OUTPUT INSERTED.UserId, INSERTED.FullName, INSERTED.Email, INSERTED.PasswordHash, INSERTED.Role, INSERTED.Status, INSERTED.EmailVerified, INSERTED.TwoFactorEnabled, INSERTED.CreatedAt VALUES (@p1, @p2, '', @p3, @p4, @p5)",
+		fullName, email, authz.RoleUser, bool(claims.EmailVerified), verifiedAt,
+	))
+}
+
+func linkIdentity(userID int, providerName, subject, email string) error {
+	_, err := config.DB.Exec(
+		"INSERT INTO UserIdentities (UserId, Provider, Subject, Email, LastLoginAt) VALUES (@p1, @p2, @p3, @p4, @p5)",
+		userID, providerName, subject, email, time.Now(),
+	)
+	return err
+}
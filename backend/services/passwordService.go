@@ -0,0 +1,277 @@
+package services
+
+import (
+	"BookMyArena/backend/breached"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/mail"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy is what signup, reset and change require of a new password. Existing passwords
+// keep working when the policy tightens; they are checked again only when next changed.
+type PasswordPolicy struct {
+	MinLength       int      `json:"minLength"` // in characters
+	MaxLength       int      `json:"maxLength"` // in bytes; bcrypt ignores anything past 72
+	RequiredClasses []string `json:"requiredClasses"`
+	BreachCheck     bool     `json:"breachCheck"`
+}
+
+// Character classes a policy can require
+const (
+	PasswordClassLower  = "lower"
+	PasswordClassUpper  = "upper"
+	PasswordClassDigit  = "digit"
+	PasswordClassSymbol = "symbol"
+)
+
+var passwordClassNames = map[string]string{
+	PasswordClassLower:  "a lowercase letter",
+	PasswordClassUpper:  "an uppercase letter",
+	PasswordClassDigit:  "a digit",
+	PasswordClassSymbol: "a symbol",
+}
+
+const (
+	passwordResetTTL = time.Hour
+
+	// Reset emails beyond this many per window are silently dropped
+	maxPasswordResetEmails   = 3
+	passwordResetEmailWindow = time.Hour
+)
+
+// newPasswordPolicy builds the policy from the password settings and loads the breached password
+// list, if one is configured
+func newPasswordPolicy(settings config.PasswordSettings) (PasswordPolicy, breached.List, error) {
+	policy := PasswordPolicy{
+		MinLength:       settings.MinLength,
+		MaxLength:       settings.MaxLength,
+		RequiredClasses: append([]string{}, settings.RequiredClasses...),
+	}
+	if settings.BreachList == "" {
+		return policy, nil, nil
+	}
+
+	list, err := breached.Load(settings.BreachList, settings.BreachMinCount)
+	if err != nil {
+		return PasswordPolicy{}, nil, fmt.Errorf("loading breached password list: %v", err)
+	}
+	policy.BreachCheck = true
+	log.Printf("Breached password list loaded from %s\n", settings.BreachList)
+	return policy, list, nil
+}
+
+func GetPasswordPolicy(deps *Deps) PasswordPolicy {
+	return deps.passwordPolicy
+}
+
+func ValidatePassword(deps *Deps, password string) error {
+	policy := deps.passwordPolicy
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("password must be at least %d characters", policy.MinLength)
+	}
+	if len(password) > policy.MaxLength {
+		return fmt.Errorf("password must be at most %d bytes", policy.MaxLength)
+	}
+
+	classes := passwordClasses(password)
+	for _, class := range policy.RequiredClasses {
+		if !classes[class] {
+			return fmt.Errorf("password must contain %s", passwordClassNames[class])
+		}
+	}
+
+	if deps.breachedPasswords != nil {
+		found, err := deps.breachedPasswords.Contains(password)
+		if err != nil {
+			// A broken list shouldn't stop people from signing up; the other rules still apply
+			log.Println("Error checking breached password list:", err)
+		} else if found {
+			return errors.New("this password has appeared in a data breach, please choose a different one")
+		}
+	}
+
+	return nil
+}
+
+func passwordClasses(password string) map[string]bool {
+	classes := make(map[string]bool)
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			classes[PasswordClassLower] = true
+		case unicode.IsUpper(r):
+			classes[PasswordClassUpper] = true
+		case unicode.IsDigit(r):
+			classes[PasswordClassDigit] = true
+		case !unicode.IsSpace(r):
+			classes[PasswordClassSymbol] = true
+		}
+	}
+	return classes
+}
+
+// RequestPasswordReset mails a reset link if the email belongs to an account. It reports success
+// either way so the endpoint can't be used to find out which addresses are registered.
+func RequestPasswordReset(deps *Deps, email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := scanUser(config.DB.QueryRow("SELECT "+userColumns+" FROM Users WHERE Email = @p1", email))
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sent, err := recentUserTokenCount(user.UserID, TokenPurposePasswordReset, passwordResetEmailWindow)
+	if err != nil {
+		return err
+	}
+	if sent >= maxPasswordResetEmails {
+		log.Printf("Password reset for user %d throttled\n", user.UserID)
+		return nil
+	}
+
+	token, err := issueUserToken(user.UserID, TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := deps.appURL("/frontend/pages/reset-password.html?token=" + url.QueryEscape(token))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your BookMyArena password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password for your BookMyArena account. To choose a new password, open this link:\n\n%s\n\nThe link expires in %d minutes and can be used once. If you didn't ask for this, you can ignore this email and your password stays the same.\n",
+			user.FullName, link, int(passwordResetTTL.Minutes()),
+		),
+	})
+}
+
+// ResetPassword sets a new password from a reset token and signs the account out everywhere.
+// Opening the link proves the user controls the inbox, so the email counts as verified too.
+func ResetPassword(deps *Deps, token, newPassword string) (*models.User, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.New("token is required")
+	}
+	if err := ValidatePassword(deps, newPassword); err != nil {
+		return nil, err
+	}
+
+	userID, err := consumeUserToken(token, TokenPurposePasswordReset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setPassword(userID, newPassword); err != nil {
+		return nil, err
+	}
+
+	_, err = config.DB.Exec(
+		"UPDATE Users SET EmailVerified = 1, EmailVerifiedAt = COALESCE(EmailVerifiedAt, @p1) WHERE UserId = @p2",
+		time.Now(), userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invalidateCachedUser(userID)
+
+	if err := DeleteUserSessions(userID, ""); err != nil {
+		return nil, err
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	notifyPasswordChanged(deps, user)
+
+	return user, nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the current one.
+// Accounts from an external provider have no password to check; with two-factor authentication on
+// they can set one with a current code, otherwise they go through "forgot password" so the email
+// address vouches for them. Every other session is revoked; currentToken, the session making the
+// change, stays signed in.
+func ChangePassword(deps *Deps, userID int, currentToken string, req models.ChangePasswordRequest) error {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.PasswordHash == "" {
+		if !user.TwoFactorEnabled {
+			return errors.New("account has no password yet, use forgot password to set one")
+		}
+		if _, err := verifySecondFactor(userID, req.Code); err != nil {
+			return err
+		}
+	} else if !VerifyPassword(user.PasswordHash, req.CurrentPassword) {
+		return errors.New("current password is incorrect")
+	}
+	if err := ValidatePassword(deps, req.NewPassword); err != nil {
+		return err
+	}
+	if user.PasswordHash != "" && req.CurrentPassword == req.NewPassword {
+		return errors.New("new password must be different from the current one")
+	}
+
+	if err := setPassword(userID, req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := DeleteUserSessions(userID, currentToken); err != nil {
+		return err
+	}
+
+	notifyPasswordChanged(deps, user)
+	return nil
+}
+
+func setPassword(userID int, password string) error {
+	passwordHash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.DB.Exec("UPDATE Users SET PasswordHash = @p1 WHERE UserId = @p2", passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	invalidateCachedUser(userID)
+
+	// Any reset link still outstanding would otherwise undo this change
+	_, err = config.DB.Exec(
+		"UPDATE UserTokens SET ExpiresAt = @p1 WHERE UserId = @p2 AND Purpose = @p3 AND UsedAt IS NULL AND ExpiresAt > @p1",
+		time.Now(), userID, TokenPurposePasswordReset,
+	)
+	return err
+}
+
+// notifyPasswordChanged tells the account owner, so an unexpected change doesn't go unnoticed
+func notifyPasswordChanged(deps *Deps, user *models.User) {
+	err := mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your BookMyArena password was changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe password for your BookMyArena account was just changed and your other sessions were signed out.\n\nIf this wasn't you, reset your password right away at %s\n",
+			user.FullName, deps.appURL("/frontend/pages/forgot-password.html"),
+		),
+	})
+	if err != nil {
+		log.Println("Error sending password change email:", err)
+	}
+}
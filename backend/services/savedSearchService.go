@@ -0,0 +1,221 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/jobs"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	maxSavedSearchesPerUser = 20
+
+	// A saved search is alerted at most once per cooldown so a burst of cancellations doesn't spam the user
+	savedSearchNotifyCooldown = 10 * time.Minute
+)
+
+func CreateSavedSearch(userID int, req models.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	req.SportType = strings.TrimSpace(req.SportType)
+	req.Location = strings.TrimSpace(req.Location)
+
+	if req.SportType == "" && req.Location == "" && req.StadiumID == 0 {
+		return nil, errors.New("a sport type, location or stadium is required")
+	}
+	if !req.WindowEnd.After(req.WindowStart) {
+		return nil, errors.New("invalid time window")
+	}
+	if req.WindowEnd.Before(time.Now()) {
+		return nil, errors.New("time window is in the past")
+	}
+	if req.Duration < 0 {
+		return nil, errors.New("invalid duration")
+	}
+
+	if req.SportType != "" {
+		if _, err := ResolveSportType(req.SportType); err != nil {
+			return nil, err
+		}
+	}
+
+	var stadiumID sql.NullInt64
+	if req.StadiumID > 0 {
+		if _, err := GetStadiumByID(req.StadiumID); err != nil {
+			return nil, err
+		}
+		stadiumID = sql.NullInt64{Int64: int64(req.StadiumID), Valid: true}
+	}
+
+	var count int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM SavedSearches WHERE UserId = @p1", userID).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxSavedSearchesPerUser {
+		return nil, fmt.Errorf("you can save at most %d searches", maxSavedSearchesPerUser)
+	}
+
+	result := config.DB.QueryRow(
+		"INSERT INTO SavedSearches (UserId, Name, SportType, Location, StadiumId, Duration, WindowStart, WindowEnd) OUTPUT INSERTED.SavedSearchId, INSERTED.UserId, INSERTED.Name, INSERTED.SportType, INSERTED.Location, INSERTED.StadiumId, INSERTED.Duration, INSERTED.WindowStart, INSERTED.WindowEnd, INSERTED.LastNotifiedAt, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8)",
+		userID, req.Name, req.SportType, req.Location, stadiumID, req.Duration, req.WindowStart, req.WindowEnd,
+	)
+
+	return scanSavedSearch(result)
+}
+
+func GetSavedSearchesByUser(userID int) ([]models.SavedSearch, error) {
+	rows, err := config.DB.Query(
+		"SELECT SavedSearchId, UserId, Name, SportType, Location, StadiumId, Duration, WindowStart, WindowEnd, LastNotifiedAt, CreatedAt FROM SavedSearches WHERE UserId = @p1 ORDER BY CreatedAt DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []models.SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, *search)
+	}
+
+	return searches, nil
+}
+
+func DeleteSavedSearch(savedSearchID, userID int) error {
+	result, err := config.DB.Exec(
+		"DELETE FROM SavedSearches WHERE SavedSearchId = @p1 AND UserId = @p2",
+		savedSearchID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("saved search not found")
+	}
+
+	return nil
+}
+
+// NotifySavedSearchesForArena alerts users whose saved search matches the arena and now has a free slot.
+// It is called after anything that can open a slot: a cancellation or a newly added arena.
+func NotifySavedSearchesForArena(deps *Deps, arenaID int) error {
+	arena, err := GetArenaByID(arenaID)
+	if err != nil {
+		return err
+	}
+	stadium, err := GetStadiumByID(arena.StadiumID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rows, err := config.DB.Query(`
+		SELECT ss.SavedSearchId, ss.UserId, ss.Name, ss.SportType, ss.Location, ss.StadiumId, ss.Duration,
+		       ss.WindowStart, ss.WindowEnd, ss.LastNotifiedAt, ss.CreatedAt
+		FROM SavedSearches ss
+		INNER JOIN Arenas a ON a.ArenaId = @p1
+		INNER JOIN Stadiums s ON s.StadiumId = a.StadiumId
+		WHERE ss.WindowEnd > @p2
+		  AND a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+		  AND (ss.LastNotifiedAt IS NULL OR ss.LastNotifiedAt < @p3)
+		  AND (ss.Location = '' OR s.Location LIKE '%' + ss.Location + '%')
+		  AND (ss.SportType = '' OR a.SportTypeId IN (SELECT SportTypeId FROM dbo.MatchSportTypes(ss.SportType)))
+		  AND (ss.StadiumId IS NULL OR ss.StadiumId = a.StadiumId)
+	`, arenaID, now, now.Add(-savedSearchNotifyCooldown))
+	if err != nil {
+		return err
+	}
+
+	var searches []models.SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		searches = append(searches, *search)
+	}
+	rows.Close()
+
+	for _, search := range searches {
+		slots, err := FindNextAvailableSlots(deps, models.SlotSearchParams{
+			ArenaID:  arenaID,
+			Duration: search.Duration,
+			From:     search.WindowStart,
+			To:       search.WindowEnd,
+			Limit:    1,
+		})
+		if err != nil || len(slots) == 0 {
+			continue
+		}
+
+		slot := slots[0]
+		message := fmt.Sprintf("A slot opened at %s (%s) on %s", arena.Name, stadium.Name, slot.SlotStart.Format("Mon 2 Jan 15:04"))
+		data := map[string]interface{}{
+			"savedSearchId": search.SavedSearchID,
+			"slot":          slot,
+		}
+
+		if err := CreateNotification(search.UserID, NotificationTypeSlotOpened, message, data); err != nil {
+			log.Println("Error creating saved search notification:", err)
+			continue
+		}
+
+		// Without this the search is notified again on the next opening, ignoring the cooldown
+		_, err = config.DB.Exec(
+			"UPDATE SavedSearches SET LastNotifiedAt = @p1 WHERE SavedSearchId = @p2",
+			now, search.SavedSearchID,
+		)
+		if err != nil {
+			log.Println("Error updating saved search notification time:", err)
+		}
+	}
+
+	return nil
+}
+
+// notifySavedSearchesAsync runs the saved search matcher without holding up the request that opened the slot
+func notifySavedSearchesAsync(deps *Deps, arenaID int) {
+	jobs.Go("saved search notifications", func() {
+		if err := NotifySavedSearchesForArena(deps, arenaID); err != nil {
+			log.Println("Error notifying saved searches:", err)
+		}
+	})
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSavedSearch(row rowScanner) (*models.SavedSearch, error) {
+	search := &models.SavedSearch{}
+	var stadiumID sql.NullInt64
+	var lastNotifiedAt sql.NullTime
+	err := row.Scan(
+		&search.SavedSearchID, &search.UserID, &search.Name, &search.SportType, &search.Location,
+		&stadiumID, &search.Duration, &search.WindowStart, &search.WindowEnd, &lastNotifiedAt, &search.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if stadiumID.Valid {
+		search.StadiumID = int(stadiumID.Int64)
+	}
+	if lastNotifiedAt.Valid {
+		search.LastNotifiedAt = &lastNotifiedAt.Time
+	}
+	return search, nil
+}
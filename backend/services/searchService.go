@@ -0,0 +1,294 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/jobs"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/search"
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SearchResultTypeArena   = "arena"
+	SearchResultTypeStadium = "stadium"
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// Full rebuilds catch changes made outside the API (e.g. manual SQL)
+	searchIndexMaxAge = 15 * time.Minute
+)
+
+var (
+	searchIndex = search.NewIndex(map[string]float64{
+		"name":        3,
+		"stadium":     2,
+		"location":    2,
+		"sportType":   2,
+		"description": 1,
+	})
+
+	searchIndexMu         sync.Mutex
+	searchIndexBuiltAt    time.Time
+	searchIndexRebuilding bool
+)
+
+func SearchCatalog(query, resultType string, limit int) ([]models.SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("search query is required")
+	}
+	if resultType != "" && resultType != SearchResultTypeArena && resultType != SearchResultTypeStadium {
+		return nil, errors.New("invalid result type")
+	}
+
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	if err := ensureSearchIndex(); err != nil {
+		return nil, err
+	}
+
+	results := []models.SearchResult{}
+	for _, match := range searchIndex.Search(query, 0) {
+		result := match.Payload.(models.SearchResult)
+		if resultType != "" && result.Type != resultType {
+			continue
+		}
+		result.Score = math.Round(match.Score*1000) / 1000
+		results = append(results, result)
+		if len(results) == limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+func RebuildSearchIndex() error {
+	docs, err := loadSearchDocuments(0)
+	if err != nil {
+		return err
+	}
+
+	searchIndex.Replace(docs)
+
+	searchIndexMu.Lock()
+	searchIndexBuiltAt = time.Now()
+	searchIndexMu.Unlock()
+
+	return nil
+}
+
+func ensureSearchIndex() error {
+	searchIndexMu.Lock()
+	builtAt := searchIndexBuiltAt
+	stale := !builtAt.IsZero() && time.Since(builtAt) > searchIndexMaxAge && !searchIndexRebuilding
+	if stale {
+		searchIndexRebuilding = true
+	}
+	searchIndexMu.Unlock()
+
+	if builtAt.IsZero() {
+		return RebuildSearchIndex()
+	}
+
+	// Keep serving the current index while a stale one is rebuilt
+	if stale {
+		jobs.Go("search index rebuild", func() {
+			defer func() {
+				searchIndexMu.Lock()
+				searchIndexRebuilding = false
+				searchIndexMu.Unlock()
+			}()
+			if err := RebuildSearchIndex(); err != nil {
+				log.Println("Error rebuilding search index:", err)
+			}
+		})
+	}
+
+	return nil
+}
+
+func searchIndexLoaded() bool {
+	searchIndexMu.Lock()
+	defer searchIndexMu.Unlock()
+	return !searchIndexBuiltAt.IsZero()
+}
+
+// syncSearchIndexForStadium re-indexes a stadium and all of its arenas after a write.
+// Documents are dropped first so anything no longer published falls out of the index.
+func syncSearchIndexForStadium(stadiumID int) {
+	if !searchIndexLoaded() {
+		return
+	}
+
+	docs, err := loadSearchDocuments(stadiumID)
+	if err != nil {
+		log.Println("Error updating search index:", err)
+		return
+	}
+
+	arenas, err := GetArenasByStadium(stadiumID)
+	if err != nil {
+		log.Println("Error updating search index:", err)
+		return
+	}
+	removeFromSearchIndex(stadiumSearchID(stadiumID))
+	for _, arena := range arenas {
+		removeFromSearchIndex(arenaSearchID(arena.ArenaID))
+	}
+
+	for _, doc := range docs {
+		searchIndex.Add(doc)
+	}
+}
+
+func removeFromSearchIndex(docIDs ...string) {
+	for _, id := range docIDs {
+		searchIndex.Remove(id)
+	}
+}
+
+func arenaSearchID(arenaID int) string {
+	return SearchResultTypeArena + ":" + strconv.Itoa(arenaID)
+}
+
+func stadiumSearchID(stadiumID int) string {
+	return SearchResultTypeStadium + ":" + strconv.Itoa(stadiumID)
+}
+
+// loadSearchDocuments builds index documents for one stadium and its arenas, or everything when stadiumID is 0
+func loadSearchDocuments(stadiumID int) ([]search.Document, error) {
+	sportTerms, err := loadSportTypeSearchTerms()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT a.ArenaId, a.StadiumId, a.Name, a.SportType, a.SportTypeId, a.Description, a.Price,
+		       s.Name AS StadiumName, s.Location
+		FROM Arenas a
+		INNER JOIN Stadiums s ON a.StadiumId = s.StadiumId
+		WHERE (@p1 = 0 OR a.StadiumId = @p1) AND a.IsPublished = 1 AND s.IsPublished = 1 AND s.Status = 'Approved'
+	`
+
+	rows, err := config.DB.Query(query, stadiumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []search.Document
+	stadiumSports := make(map[int][]string)
+	for rows.Next() {
+		var arena models.ArenaWithLocation
+		err := rows.Scan(
+			&arena.ArenaID, &arena.StadiumID, &arena.Name, &arena.SportType, &arena.SportTypeID,
+			&arena.Description, &arena.Price, &arena.StadiumName, &arena.Location,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sportText := arena.SportType + " " + sportTerms[arena.SportTypeID]
+		stadiumSports[arena.StadiumID] = append(stadiumSports[arena.StadiumID], sportText)
+
+		docs = append(docs, search.Document{
+			ID: arenaSearchID(arena.ArenaID),
+			Fields: map[string]string{
+				"name":        arena.Name,
+				"stadium":     arena.StadiumName,
+				"location":    arena.Location,
+				"sportType":   sportText,
+				"description": arena.Description,
+			},
+			Payload: models.SearchResult{
+				Type:        SearchResultTypeArena,
+				ID:          arena.ArenaID,
+				Name:        arena.Name,
+				StadiumID:   arena.StadiumID,
+				StadiumName: arena.StadiumName,
+				Location:    arena.Location,
+				SportType:   arena.SportType,
+				Price:       arena.Price,
+			},
+		})
+	}
+
+	stadiumRows, err := config.DB.Query(
+		"SELECT StadiumId, Name, Location, Description FROM Stadiums WHERE (@p1 = 0 OR StadiumId = @p1) AND IsPublished = 1 AND Status = 'Approved'",
+		stadiumID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer stadiumRows.Close()
+
+	for stadiumRows.Next() {
+		var stadium models.Stadium
+		if err := stadiumRows.Scan(&stadium.StadiumID, &stadium.Name, &stadium.Location, &stadium.Description); err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, search.Document{
+			ID: stadiumSearchID(stadium.StadiumID),
+			Fields: map[string]string{
+				"name":        stadium.Name,
+				"location":    stadium.Location,
+				"sportType":   strings.Join(stadiumSports[stadium.StadiumID], " "),
+				"description": stadium.Description,
+			},
+			Payload: models.SearchResult{
+				Type:      SearchResultTypeStadium,
+				ID:        stadium.StadiumID,
+				Name:      stadium.Name,
+				StadiumID: stadium.StadiumID,
+				Location:  stadium.Location,
+			},
+		})
+	}
+
+	return docs, nil
+}
+
+// loadSportTypeSearchTerms maps each sport type to its aliases and ancestor names,
+// so "soccer" or "football" also find futsal arenas
+func loadSportTypeSearchTerms() (map[int]string, error) {
+	sportTypes, err := GetSportTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]models.SportType, len(sportTypes))
+	for _, sportType := range sportTypes {
+		byID[sportType.SportTypeID] = sportType
+	}
+
+	terms := make(map[int]string, len(sportTypes))
+	for _, sportType := range sportTypes {
+		words := append([]string{}, sportType.Aliases...)
+		parentID := sportType.ParentID
+		for depth := 0; parentID != nil && depth < 10; depth++ {
+			parent, ok := byID[*parentID]
+			if !ok {
+				break
+			}
+			words = append(words, parent.Name)
+			words = append(words, parent.Aliases...)
+			parentID = parent.ParentID
+		}
+		terms[sportType.SportTypeID] = strings.Join(words, " ")
+	}
+
+	return terms, nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"BookMyArena/backend/cache"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// How often ValidateSession writes LastSeenAt and the new idle deadline
+	sessionTouchInterval = time.Minute
+
+	maxUserAgentLength = 500
+)
+
+// GetUserSessions lists the user's live sessions, most recently used first, flagging currentToken's
+func GetUserSessions(userID int, currentToken string) ([]models.Session, error) {
+	rows, err := config.DB.Query(`
+		SELECT SessionId, UserId, Token, UserAgent, IpAddress, RememberMe, CreatedAt, LastSeenAt, ExpiresAt, COALESCE(AbsoluteExpiresAt, ExpiresAt)
+		FROM Sessions
+		WHERE UserId = @p1 AND ExpiresAt > @p2 AND (AbsoluteExpiresAt IS NULL OR AbsoluteExpiresAt > @p2)
+		ORDER BY LastSeenAt DESC
+	`, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(&session.SessionID, &session.UserID, &session.Token, &session.UserAgent, &session.IPAddress,
+			&session.RememberMe, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.AbsoluteExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		session.Current = session.Token == currentToken
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions
+func RevokeSession(userID, sessionID int) error {
+	tokens, err := deleteSessions("DELETE FROM Sessions OUTPUT DELETED.Token WHERE SessionId = @p1 AND UserId = @p2", sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// DeleteUserSessions signs the user out of every session except keepToken, which may be empty
+func DeleteUserSessions(userID int, keepToken string) error {
+	_, err := deleteSessions("DELETE FROM Sessions OUTPUT DELETED.Token WHERE UserId = @p1 AND Token <> @p2", userID, keepToken)
+	return err
+}
+
+// deleteSessions runs a DELETE that outputs the removed tokens and drops them from the cache,
+// so a revoked session stops working at once rather than when its cache entry expires
+func deleteSessions(query string, args ...interface{}) ([]string, error) {
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	invalidateCachedSessions(tokens...)
+	return tokens, nil
+}
+
+// cachedSession is the part of a Sessions row ValidateSession needs
+type cachedSession struct {
+	UserID            int        `json:"userId"`
+	RememberMe        bool       `json:"rememberMe"`
+	ExpiresAt         time.Time  `json:"expiresAt"`
+	AbsoluteExpiresAt *time.Time `json:"absoluteExpiresAt,omitempty"`
+	LastSeenAt        time.Time  `json:"lastSeenAt"`
+}
+
+func (s *cachedSession) expired(now time.Time) bool {
+	return now.After(s.ExpiresAt) || (s.AbsoluteExpiresAt != nil && now.After(*s.AbsoluteExpiresAt))
+}
+
+// Cache keys use the token's hash so a shared store never holds usable tokens
+func sessionCacheKey(token string) string {
+	return "session:" + hashToken(token)
+}
+
+func userCacheKey(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+func cachedSessionFor(token string) (*cachedSession, bool) {
+	session := &cachedSession{}
+	if !cache.GetJSON(sessionCacheKey(token), session) {
+		return nil, false
+	}
+	return session, true
+}
+
+func loadSession(token string) (*cachedSession, error) {
+	session := &cachedSession{}
+	var absoluteExpiresAt sql.NullTime
+	err := config.DB.QueryRow(
+		"SELECT UserId, RememberMe, ExpiresAt, AbsoluteExpiresAt, LastSeenAt FROM Sessions WHERE Token = @p1",
+		token,
+	).Scan(&session.UserID, &session.RememberMe, &session.ExpiresAt, &absoluteExpiresAt, &session.LastSeenAt)
+	if err != nil {
+		return nil, err
+	}
+	if absoluteExpiresAt.Valid {
+		session.AbsoluteExpiresAt = &absoluteExpiresAt.Time
+	}
+	return session, nil
+}
+
+// cacheSession never keeps an entry past the session's own deadline
+func (deps *Deps) cacheSession(token string, session *cachedSession) {
+	ttl := minDuration(deps.cacheTTL, time.Until(session.ExpiresAt))
+	cache.SetJSON(sessionCacheKey(token), session, ttl)
+}
+
+// sessionUser loads the user behind a session. The cached copy has no PasswordHash (it isn't
+// serialized), so it is only for request authentication; anything checking a password reloads the user.
+func (deps *Deps) sessionUser(userID int) (*models.User, error) {
+	user := &models.User{}
+	if cache.GetJSON(userCacheKey(userID), user) {
+		return user, nil
+	}
+
+	user, err := scanUser(config.DB.QueryRow("SELECT "+userColumns+" FROM Users WHERE UserId = @p1", userID))
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(userCacheKey(userID), user, deps.cacheTTL)
+	return user, nil
+}
+
+func invalidateCachedSessions(tokens ...string) {
+	keys := make([]string, len(tokens))
+	for i, token := range tokens {
+		keys[i] = sessionCacheKey(token)
+	}
+	cache.Delete(keys...)
+}
+
+// invalidateCachedUser must follow every change to a Users row
+func invalidateCachedUser(userID int) {
+	cache.Delete(userCacheKey(userID))
+}
+
+func (deps *Deps) sessionTimeouts(rememberMe bool) (idle, absolute time.Duration) {
+	if rememberMe {
+		return deps.sessions.RememberIdleTimeout, deps.sessions.RememberAbsoluteTimeout
+	}
+	return deps.sessions.IdleTimeout, deps.sessions.AbsoluteTimeout
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if b < a {
+		return b
+	}
+	return a
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
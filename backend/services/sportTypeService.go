@@ -0,0 +1,111 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+func GetSportTypes() ([]models.SportType, error) {
+	rows, err := config.DB.Query(
+		"SELECT SportTypeId, Name, ParentId, CreatedAt FROM SportTypes ORDER BY Name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sportTypes []models.SportType
+	index := make(map[int]int)
+	for rows.Next() {
+		var sportType models.SportType
+		var parentID sql.NullInt64
+		err := rows.Scan(&sportType.SportTypeID, &sportType.Name, &parentID, &sportType.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if parentID.Valid {
+			id := int(parentID.Int64)
+			sportType.ParentID = &id
+		}
+		sportType.Aliases = []string{}
+		index[sportType.SportTypeID] = len(sportTypes)
+		sportTypes = append(sportTypes, sportType)
+	}
+
+	// Attach aliases to their catalog entries
+	aliasRows, err := config.DB.Query("SELECT SportTypeId, Alias FROM SportTypeAliases ORDER BY Alias")
+	if err != nil {
+		return nil, err
+	}
+	defer aliasRows.Close()
+
+	for aliasRows.Next() {
+		var sportTypeID int
+		var alias string
+		if err := aliasRows.Scan(&sportTypeID, &alias); err != nil {
+			return nil, err
+		}
+		if i, ok := index[sportTypeID]; ok {
+			sportTypes[i].Aliases = append(sportTypes[i].Aliases, alias)
+		}
+	}
+
+	return sportTypes, nil
+}
+
+func GetSportTypeByID(sportTypeID int) (*models.SportType, error) {
+	sportType := &models.SportType{}
+	var parentID sql.NullInt64
+	err := config.DB.QueryRow(
+		"SELECT SportTypeId, Name, ParentId, CreatedAt FROM SportTypes WHERE SportTypeId = @p1",
+		sportTypeID,
+	).Scan(&sportType.SportTypeID, &sportType.Name, &parentID, &sportType.CreatedAt)
+
+	if err != nil {
+		return nil, errors.New("sport type not found")
+	}
+
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		sportType.ParentID = &id
+	}
+
+	return sportType, nil
+}
+
+// ResolveSportType maps a canonical name or alias (case-insensitive) onto its catalog entry
+func ResolveSportType(name string) (*models.SportType, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("sport type is required")
+	}
+
+	var sportTypeID int
+	err := config.DB.QueryRow(
+		`SELECT TOP 1 SportTypeId FROM (
+			SELECT SportTypeId, 0 AS Priority FROM SportTypes WHERE Name = @p1
+			UNION ALL
+			SELECT SportTypeId, 1 AS Priority FROM SportTypeAliases WHERE Alias = @p1
+		) m ORDER BY Priority`,
+		name,
+	).Scan(&sportTypeID)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("unknown sport type: " + name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSportTypeByID(sportTypeID)
+}
+
+func resolveArenaSportType(req models.CreateArenaRequest) (*models.SportType, error) {
+	if req.SportTypeID > 0 {
+		return GetSportTypeByID(req.SportTypeID)
+	}
+	return ResolveSportType(req.SportType)
+}
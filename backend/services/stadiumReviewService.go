@@ -0,0 +1,177 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// A stadium starts as a Draft, is submitted for review, and only appears to customers once an admin approves it.
+// Rejected stadiums can be edited and resubmitted.
+const (
+	StadiumStatusDraft         = "Draft"
+	StadiumStatusPendingReview = "PendingReview"
+	StadiumStatusApproved      = "Approved"
+	StadiumStatusRejected      = "Rejected"
+
+	NotificationTypeStadiumReviewed = "StadiumReviewed"
+)
+
+// IsStadiumListed reports whether customers can find and book the stadium
+func IsStadiumListed(stadium *models.Stadium) bool {
+	return stadium.IsPublished && stadium.Status == StadiumStatusApproved
+}
+
+func SubmitStadiumForReview(stadiumID, userID int) (*models.Stadium, error) {
+	stadium, err := GetStadiumByID(stadiumID)
+	if err != nil {
+		return nil, err
+	}
+	if stadium.Status != StadiumStatusDraft && stadium.Status != StadiumStatusRejected {
+		return nil, fmt.Errorf("stadium can't be submitted while %s", stadium.Status)
+	}
+
+	var arenaCount int
+	err = config.DB.QueryRow("SELECT COUNT(*) FROM Arenas WHERE StadiumId = @p1", stadiumID).Scan(&arenaCount)
+	if err != nil {
+		return nil, err
+	}
+	if arenaCount == 0 {
+		return nil, errors.New("add at least one arena before submitting the stadium for review")
+	}
+
+	result, err := config.DB.Exec(
+		"UPDATE Stadiums SET Status = @p1, SubmittedAt = @p2 WHERE StadiumId = @p3 AND Status IN (@p4, @p5)",
+		StadiumStatusPendingReview, time.Now(), stadiumID, StadiumStatusDraft, StadiumStatusRejected,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.New("stadium status has changed, please reload")
+	}
+
+	return GetStadiumByID(stadiumID)
+}
+
+// GetStadiumReviewQueue lists stadiums in one review status, oldest submission first
+func GetStadiumReviewQueue(status string) ([]models.StadiumReview, error) {
+	if status == "" {
+		status = StadiumStatusPendingReview
+	}
+
+	return queryStadiumReviews("WHERE s.Status = @p1 ORDER BY COALESCE(s.SubmittedAt, s.CreatedAt)", status)
+}
+
+// GetStadiumReviewsByOwner lists the review status and notes of every stadium the user owns
+func GetStadiumReviewsByOwner(ownerID int) ([]models.StadiumReview, error) {
+	return queryStadiumReviews("WHERE s.OwnerId = @p1 ORDER BY s.CreatedAt", ownerID)
+}
+
+func queryStadiumReviews(filter string, args ...interface{}) ([]models.StadiumReview, error) {
+	rows, err := config.DB.Query(`
+		SELECT s.StadiumId, s.OwnerId, s.Name, s.Location, s.Description, s.IsPublished, s.Status, s.ReviewNote, s.SubmittedAt, s.ReviewedAt, s.RequireStaffTwoFactor, s.CreatedAt,
+		       u.FullName, u.Email, (SELECT COUNT(*) FROM Arenas a WHERE a.StadiumId = s.StadiumId) AS ArenaCount
+		FROM Stadiums s
+		INNER JOIN Users u ON s.OwnerId = u.UserId
+		`+filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.StadiumReview
+	for rows.Next() {
+		var review models.StadiumReview
+		var submittedAt, reviewedAt sql.NullTime
+		err := rows.Scan(
+			&review.StadiumID, &review.OwnerID, &review.Name, &review.Location, &review.Description,
+			&review.IsPublished, &review.Status, &review.ReviewNote, &submittedAt, &reviewedAt, &review.RequireStaffTwoFactor, &review.CreatedAt,
+			&review.OwnerName, &review.OwnerEmail, &review.ArenaCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if submittedAt.Valid {
+			review.SubmittedAt = &submittedAt.Time
+		}
+		if reviewedAt.Valid {
+			review.ReviewedAt = &reviewedAt.Time
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+func ApproveStadium(deps *Deps, adminID, stadiumID int) error {
+	stadium, err := reviewStadium(adminID, stadiumID, StadiumStatusApproved, "")
+	if err != nil {
+		return err
+	}
+
+	syncSearchIndexForStadium(stadiumID)
+	invalidateAutocomplete()
+
+	notifyStadiumReviewed(stadium, fmt.Sprintf("%s was approved and is now visible to customers", stadium.Name))
+
+	// Slots at a newly listed stadium may be what someone's saved search is waiting for
+	arenas, err := GetArenasByStadium(stadiumID)
+	if err == nil {
+		for _, arena := range arenas {
+			notifySavedSearchesAsync(deps, arena.ArenaID)
+		}
+	}
+
+	return nil
+}
+
+func RejectStadium(adminID, stadiumID int, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required")
+	}
+
+	stadium, err := reviewStadium(adminID, stadiumID, StadiumStatusRejected, reason)
+	if err != nil {
+		return err
+	}
+
+	notifyStadiumReviewed(stadium, fmt.Sprintf("%s was not approved: %s. Update it and submit it again.", stadium.Name, reason))
+
+	return nil
+}
+
+// reviewStadium moves a pending stadium to its review outcome
+func reviewStadium(adminID, stadiumID int, status, note string) (*models.Stadium, error) {
+	result, err := config.DB.Exec(
+		"UPDATE Stadiums SET Status = @p1, ReviewNote = @p2, ReviewedAt = @p3, ReviewedBy = @p4 WHERE StadiumId = @p5 AND Status = @p6",
+		status, note, time.Now(), adminID, stadiumID, StadiumStatusPendingReview,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		if _, err := GetStadiumByID(stadiumID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("stadium is not pending review")
+	}
+
+	return GetStadiumByID(stadiumID)
+}
+
+func notifyStadiumReviewed(stadium *models.Stadium, message string) {
+	data := map[string]interface{}{
+		"stadiumId": stadium.StadiumID,
+		"status":    stadium.Status,
+	}
+	if err := CreateNotification(stadium.OwnerID, NotificationTypeStadiumReviewed, message, data); err != nil {
+		log.Println("Error creating review notification:", err)
+	}
+}
@@ -3,27 +3,148 @@ package services
 import (
 	"BookMyArena/backend/config"
 	"BookMyArena/backend/models"
+	"database/sql"
 	"errors"
 )
 
 func CreateStadium(ownerID int, req models.CreateStadiumRequest) (*models.Stadium, error) {
 	result := config.DB.QueryRow(
-		"INSERT INTO Stadiums (OwnerId, Name, Location) OUTPUT INSERTED.StadiumId, INSERTED.OwnerId, INSERTED.Name, INSERTED.Location, INSERTED.CreatedAt VALUES (@p1, @p2, @p3)",
-		ownerID, req.Name, req.Location,
+		"INSERT INTO Stadiums (OwnerId, Name, Location, Description) OUTPUT INSERTED.StadiumId, INSERTED.OwnerId, INSERTED.Name, INSERTED.Location, INSERTED.Description, INSERTED.IsPublished, INSERTED.Status, INSERTED.ReviewNote, INSERTED.SubmittedAt, INSERTED.ReviewedAt, INSERTED.RequireStaffTwoFactor, INSERTED.CreatedAt VALUES (@p1, @p2, @p3, @p4)",
+		ownerID, req.Name, req.Location, req.Description,
 	)
 
-	stadium := &models.Stadium{}
-	err := result.Scan(&stadium.StadiumID, &stadium.OwnerID, &stadium.Name, &stadium.Location, &stadium.CreatedAt)
+	stadium, err := scanStadium(result)
+	if err != nil {
+		return nil, err
+	}
+
+	syncSearchIndexForStadium(stadium.StadiumID)
+	invalidateAutocomplete()
+
+	return stadium, nil
+}
+
+func UpdateStadium(stadiumID int, req models.CreateStadiumRequest) (*models.Stadium, error) {
+	// Verify stadium exists
+	if _, err := GetStadiumByID(stadiumID); err != nil {
+		return nil, err
+	}
+
+	result := config.DB.QueryRow(
+		"UPDATE Stadiums SET Name = @p1, Location = @p2, Description = @p3 OUTPUT INSERTED.StadiumId, INSERTED.OwnerId, INSERTED.Name, INSERTED.Location, INSERTED.Description, INSERTED.IsPublished, INSERTED.Status, INSERTED.ReviewNote, INSERTED.SubmittedAt, INSERTED.ReviewedAt, INSERTED.RequireStaffTwoFactor, INSERTED.CreatedAt WHERE StadiumId = @p4",
+		req.Name, req.Location, req.Description, stadiumID,
+	)
+
+	stadium, err := scanStadium(result)
+	if err != nil {
+		return nil, err
+	}
+
+	syncSearchIndexForStadium(stadium.StadiumID)
+	invalidateAutocomplete()
+
+	return stadium, nil
+}
+
+// SetStadiumStaffTwoFactor turns the staff two-factor requirement on or off. The owner has to use
+// 2FA before requiring it of anyone else; staff without it are told they've lost access until they enable it.
+func SetStadiumStaffTwoFactor(stadiumID, ownerID int, required bool) (*models.Stadium, error) {
+	if required {
+		owner, err := GetUserByID(ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if !owner.TwoFactorEnabled {
+			return nil, errors.New("enable two-factor authentication on your own account first")
+		}
+	}
+
+	result := config.DB.QueryRow(
+		"UPDATE Stadiums SET RequireStaffTwoFactor = @p1 OUTPUT INSERTED.StadiumId, INSERTED.OwnerId, INSERTED.Name, INSERTED.Location, INSERTED.Description, INSERTED.IsPublished, INSERTED.Status, INSERTED.ReviewNote, INSERTED.SubmittedAt, INSERTED.ReviewedAt, INSERTED.RequireStaffTwoFactor, INSERTED.CreatedAt WHERE StadiumId = @p2",
+		required, stadiumID,
+	)
+
+	stadium, err := scanStadium(result)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("stadium not found")
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if required {
+		notifyStaffWithoutTwoFactor(stadium)
+	}
+
 	return stadium, nil
 }
 
+func notifyStaffWithoutTwoFactor(stadium *models.Stadium) {
+	rows, err := config.DB.Query(`
+		SELECT m.UserId
+		FROM StadiumMembers m
+		INNER JOIN Users u ON u.UserId = m.UserId
+		WHERE m.StadiumId = @p1 AND m.Status = 'Active' AND u.TwoFactorEnabled = 0
+	`, stadium.StadiumID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	message := stadium.Name + " now requires two-factor authentication for staff. Enable it in your account settings to regain access."
+	for _, userID := range userIDs {
+		CreateNotification(userID, NotificationTypeStaffTwoFactorRequired, message, map[string]int{"stadiumId": stadium.StadiumID})
+	}
+}
+
+func DeleteStadium(stadiumID int) error {
+	// Check if any arena in the stadium has active bookings
+	var bookingCount int
+	err := config.DB.QueryRow(
+		`SELECT COUNT(*) FROM Bookings b
+		 INNER JOIN Arenas a ON b.ArenaId = a.ArenaId
+		 WHERE a.StadiumId = @p1 AND b.Status IN ('Pending', 'Confirmed')`,
+		stadiumID,
+	).Scan(&bookingCount)
+	if err != nil {
+		return err
+	}
+
+	if bookingCount > 0 {
+		return errors.New("cannot delete stadium with active bookings")
+	}
+
+	arenas, err := GetArenasByStadium(stadiumID)
+	if err != nil {
+		return err
+	}
+
+	// Delete the stadium (arenas and their bookings cascade)
+	_, err = config.DB.Exec("DELETE FROM Stadiums WHERE StadiumId = @p1", stadiumID)
+	if err != nil {
+		return err
+	}
+
+	removeFromSearchIndex(stadiumSearchID(stadiumID))
+	for _, arena := range arenas {
+		removeFromSearchIndex(arenaSearchID(arena.ArenaID))
+	}
+	invalidateAutocomplete()
+
+	return nil
+}
+
 func GetStadiumsByOwner(ownerID int) ([]models.Stadium, error) {
 	rows, err := config.DB.Query(
-		"SELECT StadiumId, OwnerId, Name, Location, CreatedAt FROM Stadiums WHERE OwnerId = @p1 ORDER BY CreatedAt DESC",
+		"SELECT StadiumId, OwnerId, Name, Location, Description, IsPublished, Status, ReviewNote, SubmittedAt, ReviewedAt, RequireStaffTwoFactor, CreatedAt FROM Stadiums WHERE OwnerId = @p1 ORDER BY CreatedAt DESC",
 		ownerID,
 	)
 	if err != nil {
@@ -33,24 +154,23 @@ func GetStadiumsByOwner(ownerID int) ([]models.Stadium, error) {
 
 	var stadiums []models.Stadium
 	for rows.Next() {
-		var stadium models.Stadium
-		err := rows.Scan(&stadium.StadiumID, &stadium.OwnerID, &stadium.Name, &stadium.Location, &stadium.CreatedAt)
+		stadium, err := scanStadium(rows)
 		if err != nil {
 			return nil, err
 		}
-		stadiums = append(stadiums, stadium)
+		stadiums = append(stadiums, *stadium)
 	}
 
 	return stadiums, nil
 }
 
 func GetStadiumByID(stadiumID int) (*models.Stadium, error) {
-	stadium := &models.Stadium{}
-	err := config.DB.QueryRow(
-		"SELECT StadiumId, OwnerId, Name, Location, CreatedAt FROM Stadiums WHERE StadiumId = @p1",
+	row := config.DB.QueryRow(
+		"SELECT StadiumId, OwnerId, Name, Location, Description, IsPublished, Status, ReviewNote, SubmittedAt, ReviewedAt, RequireStaffTwoFactor, CreatedAt FROM Stadiums WHERE StadiumId = @p1",
 		stadiumID,
-	).Scan(&stadium.StadiumID, &stadium.OwnerID, &stadium.Name, &stadium.Location, &stadium.CreatedAt)
+	)
 
+	stadium, err := scanStadium(row)
 	if err != nil {
 		return nil, errors.New("stadium not found")
 	}
@@ -60,7 +180,7 @@ func GetStadiumByID(stadiumID int) (*models.Stadium, error) {
 
 func GetAllStadiums() ([]models.Stadium, error) {
 	rows, err := config.DB.Query(
-		"SELECT StadiumId, OwnerId, Name, Location, CreatedAt FROM Stadiums ORDER BY CreatedAt DESC",
+		"SELECT StadiumId, OwnerId, Name, Location, Description, IsPublished, Status, ReviewNote, SubmittedAt, ReviewedAt, RequireStaffTwoFactor, CreatedAt FROM Stadiums WHERE IsPublished = 1 AND Status = 'Approved' ORDER BY CreatedAt DESC",
 	)
 	if err != nil {
 		return nil, err
@@ -69,12 +189,11 @@ func GetAllStadiums() ([]models.Stadium, error) {
 
 	var stadiums []models.Stadium
 	for rows.Next() {
-		var stadium models.Stadium
-		err := rows.Scan(&stadium.StadiumID, &stadium.OwnerID, &stadium.Name, &stadium.Location, &stadium.CreatedAt)
+		stadium, err := scanStadium(rows)
 		if err != nil {
 			return nil, err
 		}
-		stadiums = append(stadiums, stadium)
+		stadiums = append(stadiums, *stadium)
 	}
 
 	return stadiums, nil
@@ -89,3 +208,22 @@ func VerifyStadiumOwner(stadiumID, ownerID int) bool {
 	return err == nil && count > 0
 }
 
+func scanStadium(row rowScanner) (*models.Stadium, error) {
+	stadium := &models.Stadium{}
+	var submittedAt, reviewedAt sql.NullTime
+	err := row.Scan(
+		&stadium.StadiumID, &stadium.OwnerID, &stadium.Name, &stadium.Location, &stadium.Description,
+		&stadium.IsPublished, &stadium.Status, &stadium.ReviewNote, &submittedAt, &reviewedAt,
+		&stadium.RequireStaffTwoFactor, &stadium.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if submittedAt.Valid {
+		stadium.SubmittedAt = &submittedAt.Time
+	}
+	if reviewedAt.Valid {
+		stadium.ReviewedAt = &reviewedAt.Time
+	}
+	return stadium, nil
+}
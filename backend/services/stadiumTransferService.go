@@ -0,0 +1,189 @@
+package services
+
+import (
+	"BookMyArena/backend/authz"
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func RequestStadiumTransfer(stadiumID, fromOwnerID int, toEmail string) (*models.StadiumTransfer, error) {
+	if !AuthorizeStadium(fromOwnerID, stadiumID, authz.StadiumTransfer) {
+		return nil, errors.New("unauthorized: you can't transfer this stadium")
+	}
+
+	// The receiving account must be allowed to own stadiums
+	var toOwnerID int
+	var toRole string
+	err := config.DB.QueryRow("SELECT UserId, Role FROM Users WHERE Email = @p1", toEmail).Scan(&toOwnerID, &toRole)
+	if err != nil {
+		return nil, errors.New("receiving owner not found")
+	}
+	if !authz.RoleHas(toRole, authz.StadiumOwn) {
+		return nil, errors.New("receiving account must be an owner")
+	}
+	if toOwnerID == fromOwnerID {
+		return nil, errors.New("cannot transfer a stadium to yourself")
+	}
+
+	var pending int
+	err = config.DB.QueryRow(
+		"SELECT COUNT(*) FROM StadiumTransfers WHERE StadiumId = @p1 AND Status = 'Pending'",
+		stadiumID,
+	).Scan(&pending)
+	if err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, errors.New("a transfer is already pending for this stadium")
+	}
+
+	result := config.DB.QueryRow(
+		"INSERT INTO StadiumTransfers (StadiumId, FromOwnerId, ToOwnerId) OUTPUT INSERTED.TransferId, INSERTED.StadiumId, INSERTED.FromOwnerId, INSERTED.ToOwnerId, INSERTED.Status, INSERTED.CreatedAt, INSERTED.RespondedAt VALUES (@p1, @p2, @p3)",
+		stadiumID, fromOwnerID, toOwnerID,
+	)
+
+	return scanStadiumTransfer(result)
+}
+
+func GetStadiumTransfersForUser(userID int) ([]models.StadiumTransferWithDetails, error) {
+	query := `
+		SELECT t.TransferId, t.StadiumId, t.FromOwnerId, t.ToOwnerId, t.Status, t.CreatedAt, t.RespondedAt,
+		       s.Name AS StadiumName, fu.Email AS FromOwnerEmail, tu.Email AS ToOwnerEmail
+		FROM StadiumTransfers t
+		INNER JOIN Stadiums s ON t.StadiumId = s.StadiumId
+		INNER JOIN Users fu ON t.FromOwnerId = fu.UserId
+		INNER JOIN Users tu ON t.ToOwnerId = tu.UserId
+		WHERE t.FromOwnerId = @p1 OR t.ToOwnerId = @p1
+		ORDER BY t.CreatedAt DESC
+	`
+
+	rows, err := config.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []models.StadiumTransferWithDetails
+	for rows.Next() {
+		var transfer models.StadiumTransferWithDetails
+		var respondedAt sql.NullTime
+		err := rows.Scan(
+			&transfer.TransferID, &transfer.StadiumID, &transfer.FromOwnerID, &transfer.ToOwnerID,
+			&transfer.Status, &transfer.CreatedAt, &respondedAt,
+			&transfer.StadiumName, &transfer.FromOwnerEmail, &transfer.ToOwnerEmail,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if respondedAt.Valid {
+			transfer.RespondedAt = &respondedAt.Time
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+func GetStadiumTransferByID(transferID int) (*models.StadiumTransfer, error) {
+	row := config.DB.QueryRow(
+		"SELECT TransferId, StadiumId, FromOwnerId, ToOwnerId, Status, CreatedAt, RespondedAt FROM StadiumTransfers WHERE TransferId = @p1",
+		transferID,
+	)
+
+	transfer, err := scanStadiumTransfer(row)
+	if err != nil {
+		return nil, errors.New("transfer not found")
+	}
+
+	return transfer, nil
+}
+
+// RespondToStadiumTransfer lets the receiving owner accept or decline. Ownership only changes on accept.
+func RespondToStadiumTransfer(transferID, userID int, accept bool) error {
+	transfer, err := GetStadiumTransferByID(transferID)
+	if err != nil {
+		return err
+	}
+
+	if transfer.ToOwnerID != userID {
+		return errors.New("unauthorized: transfer is not addressed to you")
+	}
+	if transfer.Status != "Pending" {
+		return errors.New("transfer is no longer pending")
+	}
+
+	if !accept {
+		_, err = config.DB.Exec(
+			"UPDATE StadiumTransfers SET Status = 'Declined', RespondedAt = @p1 WHERE TransferId = @p2 AND Status = 'Pending'",
+			time.Now(), transferID,
+		)
+		return err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only move the stadium if the sender still owns it
+	result, err := tx.Exec(
+		"UPDATE Stadiums SET OwnerId = @p1 WHERE StadiumId = @p2 AND OwnerId = @p3",
+		transfer.ToOwnerID, transfer.StadiumID, transfer.FromOwnerID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.New("stadium ownership has changed since the transfer was requested")
+	}
+
+	result, err = tx.Exec(
+		"UPDATE StadiumTransfers SET Status = 'Accepted', RespondedAt = @p1 WHERE TransferId = @p2 AND Status = 'Pending'",
+		time.Now(), transferID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.New("transfer is no longer pending")
+	}
+
+	return tx.Commit()
+}
+
+func CancelStadiumTransfer(transferID, userID int) error {
+	transfer, err := GetStadiumTransferByID(transferID)
+	if err != nil {
+		return err
+	}
+
+	if transfer.FromOwnerID != userID {
+		return errors.New("unauthorized: only the sending owner can cancel a transfer")
+	}
+	if transfer.Status != "Pending" {
+		return errors.New("transfer is no longer pending")
+	}
+
+	_, err = config.DB.Exec(
+		"UPDATE StadiumTransfers SET Status = 'Cancelled', RespondedAt = @p1 WHERE TransferId = @p2 AND Status = 'Pending'",
+		time.Now(), transferID,
+	)
+	return err
+}
+
+func scanStadiumTransfer(row rowScanner) (*models.StadiumTransfer, error) {
+	transfer := &models.StadiumTransfer{}
+	var respondedAt sql.NullTime
+	err := row.Scan(&transfer.TransferID, &transfer.StadiumID, &transfer.FromOwnerID, &transfer.ToOwnerID, &transfer.Status, &transfer.CreatedAt, &respondedAt)
+	if err != nil {
+		return nil, err
+	}
+	if respondedAt.Valid {
+		transfer.RespondedAt = &respondedAt.Time
+	}
+	return transfer, nil
+}
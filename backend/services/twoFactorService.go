@@ -0,0 +1,339 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/models"
+	"BookMyArena/backend/totp"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	twoFactorIssuer = "BookMyArena"
+
+	recoveryCodeCount = 10
+
+	loginChallengeTTL         = 5 * time.Minute
+	maxLoginChallengeAttempts = 5
+)
+
+// Second factors a login challenge accepts
+const (
+	SecondFactorTOTP         = "totp"
+	SecondFactorRecoveryCode = "recovery_code"
+)
+
+// BeginTwoFactorSetup creates a new secret for the user to add to an authenticator app. It takes effect
+// only once EnableTwoFactor confirms a code from it; starting over replaces any unconfirmed secret.
+func BeginTwoFactorSetup(userID int) (*models.TwoFactorSetup, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = config.DB.Exec(`
+		MERGE UserTwoFactor AS target
+		USING (SELECT @p1 AS UserId) AS source ON target.UserId = source.UserId
+		WHEN MATCHED THEN UPDATE SET Secret = @p2, EnabledAt = NULL, LastUsedStep = 0, CreatedAt = @p3
+		WHEN NOT MATCHED THEN INSERT (UserId, Secret, CreatedAt) VALUES (@p1, @p2, @p3);
+	`, userID, secret, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, twoFactorIssuer, user.Email),
+	}, nil
+}
+
+// EnableTwoFactor confirms enrollment with a code from the app and returns a fresh set of recovery codes.
+// The codes are only ever shown here; just their hashes are kept.
+func EnableTwoFactor(userID int, code string) ([]string, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	var secret string
+	var lastStep int64
+	err = config.DB.QueryRow("SELECT Secret, LastUsedStep FROM UserTwoFactor WHERE UserId = @p1", userID).Scan(&secret, &lastStep)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("start two-factor setup first")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), lastStep)
+	if !ok {
+		return nil, errors.New("invalid code")
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE UserTwoFactor SET EnabledAt = @p1, LastUsedStep = @p2 WHERE UserId = @p3", time.Now(), step, userID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec("UPDATE Users SET TwoFactorEnabled = 1 WHERE UserId = @p1", userID)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	invalidateCachedUser(userID)
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns 2FA off. It asks for the password as well as a code so a stolen session alone can't do it.
+// Accounts from an external provider have no password, so for them the code is the proof.
+func DisableTwoFactor(userID int, password, code string) error {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if user.PasswordHash != "" && !VerifyPassword(user.PasswordHash, password) {
+		return errors.New("password is incorrect")
+	}
+	if _, err := verifySecondFactor(userID, code); err != nil {
+		return err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE Users SET TwoFactorEnabled = 0 WHERE UserId = @p1", userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM UserTwoFactor WHERE UserId = @p1", userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM UserRecoveryCodes WHERE UserId = @p1", userID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	invalidateCachedUser(userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not, after checking a current code
+func RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+	if _, err := verifySecondFactor(userID, code); err != nil {
+		return nil, err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// StartLoginChallenge is issued instead of a session when a correct password belongs to an account
+// with 2FA. The client trades it, with a code, for a session through CompleteLoginChallenge.
+func StartLoginChallenge(userID int) (string, error) {
+	return issueUserToken(userID, TokenPurposeLoginChallenge, loginChallengeTTL)
+}
+
+// LoginChallengeRetryAfter is LoginRetryAfter for the second step of a login, checked against the
+// account the challenge belongs to. An unknown challenge is only checked against the address;
+// CompleteLoginChallenge rejects it anyway.
+func LoginChallengeRetryAfter(challengeToken, ipAddress string) (time.Duration, error) {
+	email := ""
+	if _, userID, err := findUserToken(challengeToken, TokenPurposeLoginChallenge); err == nil {
+		user, err := GetUserByID(userID)
+		if err != nil {
+			return 0, err
+		}
+		email = user.Email
+	}
+	return LoginRetryAfter(email, ipAddress)
+}
+
+// CompleteLoginChallenge checks the second factor for a pending login and returns the user along with
+// which factor was used. A challenge allows a handful of wrong codes before the user has to start over.
+// Wrong codes also count towards the login throttle for the account and the address, so starting a
+// new challenge doesn't buy more guesses; callers check LoginChallengeRetryAfter first.
+func CompleteLoginChallenge(deps *Deps, challengeToken, code, ipAddress string) (*models.User, string, error) {
+	tokenID, userID, err := findUserToken(challengeToken, TokenPurposeLoginChallenge)
+	if err != nil {
+		return nil, "", errors.New("login challenge is invalid or has expired, please log in again")
+	}
+	if err := claimUserTokenAttempt(tokenID, maxLoginChallengeAttempts); err != nil {
+		if err == errUserTokenAttemptsExhausted {
+			return nil, "", errors.New("too many incorrect codes, please log in again")
+		}
+		return nil, "", err
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	method, err := verifySecondFactor(userID, code)
+	if err != nil {
+		recordLoginFailure(deps, user.Email, ipAddress, user)
+		return nil, "", err
+	}
+
+	if err := useUserToken(tokenID); err != nil {
+		return nil, "", errors.New("login challenge is invalid or has expired, please log in again")
+	}
+
+	if user.Status == UserStatusSuspended {
+		return nil, "", errors.New("account is suspended")
+	}
+
+	recordLoginSuccess(user.Email)
+	return user, method, nil
+}
+
+// verifySecondFactor accepts a TOTP code or an unused recovery code and reports which one matched.
+// Each TOTP code works once and each recovery code is spent on use.
+func verifySecondFactor(userID int, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", errors.New("code is required")
+	}
+
+	var secret string
+	var lastStep int64
+	err := config.DB.QueryRow(
+		"SELECT Secret, LastUsedStep FROM UserTwoFactor WHERE UserId = @p1 AND EnabledAt IS NOT NULL",
+		userID,
+	).Scan(&secret, &lastStep)
+	if err == sql.ErrNoRows {
+		return "", errors.New("two-factor authentication is not enabled")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if step, ok := totp.Validate(secret, code, time.Now(), lastStep); ok {
+		// Conditional so two requests racing with the same code can't both succeed
+		result, err := config.DB.Exec(
+			"UPDATE UserTwoFactor SET LastUsedStep = @p1 WHERE UserId = @p2 AND LastUsedStep < @p1",
+			step, userID,
+		)
+		if err != nil {
+			return "", err
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			return SecondFactorTOTP, nil
+		}
+		return "", errors.New("invalid code")
+	}
+
+	result, err := config.DB.Exec(
+		"UPDATE UserRecoveryCodes SET UsedAt = @p1 WHERE UserId = @p2 AND CodeHash = @p3 AND UsedAt IS NULL",
+		time.Now(), userID, hashToken(normalizeRecoveryCode(code)),
+	)
+	if err != nil {
+		return "", err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		return SecondFactorRecoveryCode, nil
+	}
+
+	return "", errors.New("invalid code")
+}
+
+// replaceRecoveryCodes swaps the user's recovery codes for a new set within tx, so a failure part way
+// leaves the old codes in place rather than a partial set
+func replaceRecoveryCodes(tx *sql.Tx, userID int) ([]string, error) {
+	_, err := tx.Exec("DELETE FROM UserRecoveryCodes WHERE UserId = @p1", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO UserRecoveryCodes (UserId, CodeHash) VALUES (@p1, @p2)",
+			userID, hashToken(normalizeRecoveryCode(code)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns 10 random base32 characters formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 7)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode makes codes match however they were typed
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
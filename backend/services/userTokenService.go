@@ -0,0 +1,127 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Purposes for tokens mailed to users. A token only redeems for the purpose it was issued for.
+const (
+	TokenPurposeEmailVerification = "EmailVerification"
+	TokenPurposePasswordReset     = "PasswordReset"
+	TokenPurposeLoginChallenge    = "LoginChallenge"
+	TokenPurposeEmailChange       = "EmailChange"
+)
+
+// issueUserToken creates a single-use token and returns it in plain form for the email link.
+// Older unused tokens for the same purpose stop working, so only the latest link is valid.
+func issueUserToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	token, err := GenerateSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	// Expire rather than delete them so recentUserTokenCount still sees them
+	now := time.Now()
+	_, err = config.DB.Exec(
+		"UPDATE UserTokens SET ExpiresAt = @p1 WHERE UserId = @p2 AND Purpose = @p3 AND UsedAt IS NULL AND ExpiresAt > @p1",
+		now, userID, purpose,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = config.DB.Exec(
+		"INSERT INTO UserTokens (UserId, Purpose, TokenHash, ExpiresAt) VALUES (@p1, @p2, @p3, @p4)",
+		userID, purpose, hashToken(token), now.Add(ttl),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeUserToken marks the token used and returns its user. The update is conditional so a token
+// redeems at most once even if the link is opened twice at the same moment.
+func consumeUserToken(token, purpose string) (int, error) {
+	var userID int
+	err := config.DB.QueryRow(`
+		UPDATE UserTokens SET UsedAt = @p1
+		OUTPUT INSERTED.UserId
+		WHERE TokenHash = @p2 AND Purpose = @p3 AND UsedAt IS NULL AND ExpiresAt > @p1
+	`, time.Now(), hashToken(token), purpose).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("this link is invalid or has expired")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// findUserToken looks up a live token without using it up, for flows that verify something else
+// (e.g. a second factor) before redeeming it with useUserToken
+func findUserToken(token, purpose string) (tokenID, userID int, err error) {
+	err = config.DB.QueryRow(`
+		SELECT TokenId, UserId FROM UserTokens
+		WHERE TokenHash = @p1 AND Purpose = @p2 AND UsedAt IS NULL AND ExpiresAt > @p3
+	`, hashToken(token), purpose, time.Now()).Scan(&tokenID, &userID)
+	if err == sql.ErrNoRows {
+		return 0, 0, errors.New("this link is invalid or has expired")
+	}
+	return tokenID, userID, err
+}
+
+// errUserTokenAttemptsExhausted means the token has had its maxAttempts tries
+var errUserTokenAttemptsExhausted = errors.New("too many attempts")
+
+// claimUserTokenAttempt counts one try against the token before the caller checks anything. The
+// check and the increment are a single statement, so parallel requests can't exceed maxAttempts.
+func claimUserTokenAttempt(tokenID, maxAttempts int) error {
+	var attempts int
+	err := config.DB.QueryRow(`
+		UPDATE UserTokens SET Attempts = Attempts + 1
+		OUTPUT INSERTED.Attempts
+		WHERE TokenId = @p1 AND Attempts < @p2 AND UsedAt IS NULL
+	`, tokenID, maxAttempts).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return errUserTokenAttemptsExhausted
+	}
+	return err
+}
+
+// useUserToken redeems a token found with findUserToken, failing if it was used in the meantime
+func useUserToken(tokenID int) error {
+	result, err := config.DB.Exec(
+		"UPDATE UserTokens SET UsedAt = @p1 WHERE TokenId = @p2 AND UsedAt IS NULL",
+		time.Now(), tokenID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.New("this link is invalid or has expired")
+	}
+	return nil
+}
+
+// recentUserTokenCount counts tokens issued for the purpose within the window, used or not
+func recentUserTokenCount(userID int, purpose string, window time.Duration) (int, error) {
+	var count int
+	err := config.DB.QueryRow(
+		"SELECT COUNT(*) FROM UserTokens WHERE UserId = @p1 AND Purpose = @p2 AND CreatedAt > @p3",
+		userID, purpose, time.Now().Add(-window),
+	).Scan(&count)
+	return count, err
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
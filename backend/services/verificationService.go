@@ -0,0 +1,86 @@
+package services
+
+import (
+	"BookMyArena/backend/config"
+	"BookMyArena/backend/mail"
+	"BookMyArena/backend/models"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+
+	// At most maxVerificationEmails per verificationEmailWindow, so resend can't be used to spam an inbox
+	maxVerificationEmails   = 3
+	verificationEmailWindow = time.Hour
+)
+
+// SendVerificationEmail mails the user a link that confirms they own their address
+func SendVerificationEmail(deps *Deps, user *models.User) error {
+	token, err := issueUserToken(user.UserID, TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := deps.appURL("/frontend/pages/verify-email.html?token=" + url.QueryEscape(token))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your BookMyArena email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\nThe link expires in %d hours. If you didn't sign up for BookMyArena you can ignore this email.\n",
+			user.FullName, link, int(emailVerificationTTL.Hours()),
+		),
+	})
+}
+
+func ResendVerificationEmail(deps *Deps, userID int) error {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.New("email is already verified")
+	}
+
+	sent, err := recentUserTokenCount(userID, TokenPurposeEmailVerification, verificationEmailWindow)
+	if err != nil {
+		return err
+	}
+	if sent >= maxVerificationEmails {
+		return errors.New("too many verification emails, please try again later")
+	}
+
+	return SendVerificationEmail(deps, user)
+}
+
+// VerifyEmail redeems a verification token and marks the account's email as verified
+func VerifyEmail(token string) (*models.User, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.New("token is required")
+	}
+
+	userID, err := consumeUserToken(token, TokenPurposeEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = config.DB.Exec(
+		"UPDATE Users SET EmailVerified = 1, EmailVerifiedAt = @p1 WHERE UserId = @p2",
+		time.Now(), userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invalidateCachedUser(userID)
+
+	return GetUserByID(userID)
+}
+
+// appURL builds an absolute link to the web app for use in emails
+func (deps *Deps) appURL(path string) string {
+	return deps.baseURL + path
+}
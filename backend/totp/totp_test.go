@@ -0,0 +1,119 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// The SHA-1 key from RFC 6238 appendix B, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC's published 8-digit SHA-1 codes, of which a 6-digit code is the last six digits
+func TestCodeRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; got != want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / int64(Period.Seconds())
+	codeAt := func(t time.Time) string {
+		code, _ := Code(rfcSecret, t)
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfcSecret, "050471", 0, step, true},
+		{"previous step for clock drift", rfcSecret, codeAt(now.Add(-Period)), 0, step - 1, true},
+		{"next step for clock drift", rfcSecret, codeAt(now.Add(Period)), 0, step + 1, true},
+		{"two steps old", rfcSecret, codeAt(now.Add(-2 * Period)), 0, 0, false},
+		{"two steps ahead", rfcSecret, codeAt(now.Add(2 * Period)), 0, 0, false},
+		{"spaces and padding ignored", rfcSecret, " 050 471 ", 0, step, true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", 0, step, true},
+		{"replayed step", rfcSecret, "050471", step, 0, false},
+		{"earlier step still open", rfcSecret, codeAt(now.Add(-Period)), step - 2, step - 1, true},
+		{"wrong code", rfcSecret, "123456", 0, 0, false},
+		{"too short", rfcSecret, "05047", 0, 0, false},
+		{"too long", rfcSecret, "14050471", 0, 0, false},
+		{"invalid secret", "not base32!", "050471", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := Validate(tt.secret, tt.code, now, tt.lastStep)
+			if gotStep != tt.wantStep || gotOK != tt.wantOK {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != secretSz {
+		t.Errorf("secret decodes to %d bytes, want %d", len(key), secretSz)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("two generated secrets are equal")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI(rfcSecret, "BookMyArena", "ali@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" {
+		t.Errorf("URI starts %s://%s, want otpauth://totp", uri.Scheme, uri.Host)
+	}
+	if uri.Path != "/BookMyArena:ali@example.com" {
+		t.Errorf("label = %q, want /BookMyArena:ali@example.com", uri.Path)
+	}
+
+	params := uri.Query()
+	for key, want := range map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "BookMyArena",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	} {
+		if got := params.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// client is what ResolveClient worked out about where a request came from
+type client struct {
+	ip     string
+	secure bool
+}
+
+type clientContextKey struct{}
+
+// ResolveClient records the request's client address and scheme for ClientIP and IsSecureRequest.
+// Forwarding headers are only believed when the direct peer is one of the trusted proxies, since
+// anyone else can set them. Proxies append to X-Forwarded-For, so its entries are read from the
+// right: the first one that isn't a trusted proxy is the client. Entries further left were written
+// by the client itself and are ignored.
+func ResolveClient(r *http.Request, trusted []*net.IPNet) *http.Request {
+	info := client{ip: peerHost(r), secure: r.TLS != nil}
+
+	if isTrusted(net.ParseIP(info.ip), trusted) {
+		if hops := forwardedValues(r, "X-Forwarded-For"); len(hops) > 0 {
+			for i := len(hops) - 1; i >= 0; i-- {
+				ip := net.ParseIP(hops[i])
+				if ip == nil {
+					// Can't tell who wrote anything left of here; the last trusted hop is the best we know
+					break
+				}
+				info.ip = ip.String()
+				if !isTrusted(ip, trusted) {
+					break
+				}
+			}
+		} else if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+			info.ip = realIP.String()
+		}
+
+		// The right-most scheme is the one our proxy saw
+		if protos := forwardedValues(r, "X-Forwarded-Proto"); len(protos) > 0 {
+			info.secure = info.secure || strings.EqualFold(protos[len(protos)-1], "https")
+		}
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), clientContextKey{}, info))
+}
+
+// ClientIP returns the address the request came from, as resolved by ResolveClient. Without that
+// it is the direct peer.
+func ClientIP(r *http.Request) string {
+	if info, ok := r.Context().Value(clientContextKey{}).(client); ok {
+		return info.ip
+	}
+	return peerHost(r)
+}
+
+// IsSecureRequest reports whether the client reached us over HTTPS, directly or through a trusted proxy
+func IsSecureRequest(r *http.Request) bool {
+	if info, ok := r.Context().Value(clientContextKey{}).(client); ok {
+		return info.secure
+	}
+	return r.TLS != nil
+}
+
+func peerHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedValues splits every copy of a comma-separated header into its trimmed entries, in order
+func forwardedValues(r *http.Request, name string) []string {
+	var values []string
+	for _, header := range r.Header.Values(name) {
+		for _, value := range strings.Split(header, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	return values
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveClient(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/24")
+	_, loopback, _ := net.ParseCIDR("127.0.0.1/32")
+	trusted := []*net.IPNet{proxies, loopback}
+
+	tests := []struct {
+		name       string
+		peer       string
+		forwarded  []string
+		realIP     string
+		proto      string
+		tls        bool
+		wantIP     string
+		wantSecure bool
+	}{
+		{name: "direct client", peer: "203.0.113.7:5000", wantIP: "203.0.113.7"},
+		{name: "headers from an untrusted peer are ignored", peer: "203.0.113.7:5000", forwarded: []string{"198.51.100.1"}, proto: "https", wantIP: "203.0.113.7"},
+		{name: "private but not configured", peer: "192.168.1.5:5000", forwarded: []string{"198.51.100.1"}, proto: "https", wantIP: "192.168.1.5"},
+		{name: "one proxy", peer: "10.0.0.2:5000", forwarded: []string{"198.51.100.1"}, proto: "https", wantIP: "198.51.100.1", wantSecure: true},
+		{name: "spoofed entries left of the client are ignored", peer: "10.0.0.2:5000", forwarded: []string{"1.2.3.4, 198.51.100.1"}, wantIP: "198.51.100.1"},
+		{name: "chain of proxies", peer: "127.0.0.1:5000", forwarded: []string{"1.2.3.4, 198.51.100.1, 10.0.0.9"}, wantIP: "198.51.100.1"},
+		{name: "repeated headers", peer: "10.0.0.2:5000", forwarded: []string{"1.2.3.4", "198.51.100.1"}, wantIP: "198.51.100.1"},
+		{name: "garbage stops the walk", peer: "10.0.0.2:5000", forwarded: []string{"198.51.100.1, unknown, 10.0.0.9"}, wantIP: "10.0.0.9"},
+		{name: "only proxies", peer: "10.0.0.2:5000", forwarded: []string{"10.0.0.7, 10.0.0.9"}, wantIP: "10.0.0.7"},
+		{name: "X-Real-IP without X-Forwarded-For", peer: "10.0.0.2:5000", realIP: "198.51.100.1", wantIP: "198.51.100.1"},
+		{name: "right-most scheme wins", peer: "10.0.0.2:5000", proto: "https, http", wantIP: "10.0.0.2"},
+		{name: "TLS to us directly", peer: "203.0.113.7:5000", tls: true, wantIP: "203.0.113.7", wantSecure: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.peer
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if tt.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+
+			r = ResolveClient(r, trusted)
+			if got := ClientIP(r); got != tt.wantIP {
+				t.Errorf("ClientIP() = %s, want %s", got, tt.wantIP)
+			}
+			if got := IsSecureRequest(r); got != tt.wantSecure {
+				t.Errorf("IsSecureRequest() = %v, want %v", got, tt.wantSecure)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutResolve(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "127.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.Header.Set("X-Forwarded-Proto", "https")
+
+	if got := ClientIP(r); got != "127.0.0.1" {
+		t.Errorf("ClientIP() = %s, want the peer", got)
+	}
+	if IsSecureRequest(r) {
+		t.Error("IsSecureRequest() believed X-Forwarded-Proto without a trusted proxy")
+	}
+}
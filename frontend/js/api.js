@@ -2,6 +2,11 @@
 const API = {
     baseURL: '', // Relative URL since frontend and backend are on same server
 
+    cookie(name) {
+        const match = document.cookie.split('; ').find(entry => entry.startsWith(name + '='));
+        return match ? decodeURIComponent(match.slice(name.length + 1)) : null;
+    },
+
     async request(endpoint, options = {}) {
         const url = this.baseURL + endpoint;
         const defaultOptions = {
@@ -17,6 +22,15 @@ const API = {
             defaultOptions.headers['Authorization'] = `Bearer ${token}`;
         }
 
+        // Echo the CSRF cookie on state-changing requests; the server rejects cookie-authenticated ones without it
+        const method = (options.method || 'GET').toUpperCase();
+        if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+            const csrfToken = this.cookie('csrf_token');
+            if (csrfToken) {
+                defaultOptions.headers['X-CSRF-Token'] = csrfToken;
+            }
+        }
+
         const config = {
             ...defaultOptions,
             ...options,
@@ -58,18 +72,175 @@ const API = {
         });
     },
 
+    async loginTwoFactor(challengeToken, code, rememberMe) {
+        return this.request('/api/login/2fa', {
+            method: 'POST',
+            body: JSON.stringify({ challengeToken, code, rememberMe }),
+        });
+    },
+
+    async getLoginProviders() {
+        return this.request('/api/auth/oidc/providers', {
+            method: 'GET',
+        });
+    },
+
+    // Provider logins are full-page redirects, not fetches
+    providerLoginURL(provider, rememberMe) {
+        const query = rememberMe ? '?rememberMe=true' : '';
+        return `${this.baseURL}/api/auth/oidc/${encodeURIComponent(provider)}/login${query}`;
+    },
+
     async logout() {
         return this.request('/api/logout', {
+            method: 'POST',
+        });
+    },
+
+    async verifyEmail(token) {
+        return this.request('/api/verify-email', {
+            method: 'POST',
+            body: JSON.stringify({ token }),
+        });
+    },
+
+    async resendVerificationEmail() {
+        return this.request('/api/verify-email/resend', {
+            method: 'POST',
+        });
+    },
+
+    async forgotPassword(email) {
+        return this.request('/api/forgot-password', {
+            method: 'POST',
+            body: JSON.stringify({ email }),
+        });
+    },
+
+    async getPasswordPolicy() {
+        return this.request('/api/password-policy', {
             method: 'GET',
         });
     },
 
+    async resetPassword(token, password) {
+        return this.request('/api/reset-password', {
+            method: 'POST',
+            body: JSON.stringify({ token, password }),
+        });
+    },
+
+    async changePassword(currentPassword, newPassword) {
+        return this.request('/api/user/password', {
+            method: 'PUT',
+            body: JSON.stringify({ currentPassword, newPassword }),
+        });
+    },
+
+    async getLinkedIdentities() {
+        return this.request('/api/user/identities', {
+            method: 'GET',
+        });
+    },
+
+    async beginTwoFactorSetup() {
+        return this.request('/api/user/2fa/setup', {
+            method: 'POST',
+        });
+    },
+
+    async enableTwoFactor(code) {
+        return this.request('/api/user/2fa/enable', {
+            method: 'POST',
+            body: JSON.stringify({ code }),
+        });
+    },
+
+    async disableTwoFactor(password, code) {
+        return this.request('/api/user/2fa/disable', {
+            method: 'POST',
+            body: JSON.stringify({ password, code }),
+        });
+    },
+
+    async regenerateRecoveryCodes(code) {
+        return this.request('/api/user/2fa/recovery-codes', {
+            method: 'POST',
+            body: JSON.stringify({ code }),
+        });
+    },
+
+    async createApiKey(name, scopes, expiresAt) {
+        return this.request('/api/api-keys', {
+            method: 'POST',
+            body: JSON.stringify({ name, scopes, expiresAt }),
+        });
+    },
+
+    async getApiKeys() {
+        return this.request('/api/api-keys', {
+            method: 'GET',
+        });
+    },
+
+    async revokeApiKey(keyId) {
+        return this.request(`/api/api-keys/${keyId}`, {
+            method: 'DELETE',
+        });
+    },
+
+    async getSessions() {
+        return this.request('/api/sessions', {
+            method: 'GET',
+        });
+    },
+
+    async revokeSession(sessionId) {
+        return this.request(`/api/sessions/${sessionId}`, {
+            method: 'DELETE',
+        });
+    },
+
+    async revokeOtherSessions() {
+        return this.request('/api/sessions', {
+            method: 'DELETE',
+        });
+    },
+
     async getCurrentUser() {
         return this.request('/api/user', {
             method: 'GET',
         });
     },
 
+    // password is only needed when changing the email
+    async updateProfile(fullName, email, password) {
+        return this.request('/api/user', {
+            method: 'PUT',
+            body: JSON.stringify({ fullName, email, password }),
+        });
+    },
+
+    async confirmEmailChange(token) {
+        return this.request('/api/user/email/confirm', {
+            method: 'POST',
+            body: JSON.stringify({ token }),
+        });
+    },
+
+    async deleteAccount(password, code) {
+        return this.request('/api/user', {
+            method: 'DELETE',
+            body: JSON.stringify({ password, code }),
+        });
+    },
+
+    async exportAccountData() {
+        return this.request('/api/user/export', {
+            method: 'GET',
+        });
+    },
+
     // Stadium endpoints
     async createStadium(stadiumData) {
         return this.request('/api/stadiums', {
@@ -78,6 +249,13 @@ const API = {
         });
     },
 
+    async updateStadiumSecurity(id, requireStaffTwoFactor) {
+        return this.request(`/api/stadiums/${id}/security`, {
+            method: 'PUT',
+            body: JSON.stringify({ requireStaffTwoFactor }),
+        });
+    },
+
     async getStadiums() {
         return this.request('/api/stadiums', {
             method: 'GET',
@@ -91,6 +269,12 @@ const API = {
     },
 
     // Arena endpoints
+    async submitStadium(id) {
+        return this.request(`/api/stadiums/${id}/submit`, {
+            method: 'PUT',
+        });
+    },
+
     async createArena(arenaData) {
         return this.request('/api/arenas', {
             method: 'POST',
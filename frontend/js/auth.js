@@ -8,7 +8,7 @@ document.addEventListener('DOMContentLoaded', async () => {
     const currentPage = window.location.pathname;
 
     // Pages that don't require authentication
-    const publicPages = ['/signup.html', '/login.html', '/'];
+    const publicPages = ['/signup.html', '/login.html', '/verify-email.html', '/forgot-password.html', '/reset-password.html', '/'];
 
     const isPublicPage = publicPages.some(page => currentPage.endsWith(page));
 
@@ -18,6 +18,18 @@ document.addEventListener('DOMContentLoaded', async () => {
         return;
     }
 
+    if (!token && currentPage.endsWith('/login.html')) {
+        // A "remember me" login outlives the tab through the session cookie, so pick it back up
+        try {
+            const currentUser = await API.getCurrentUser();
+            sessionStorage.setItem('user', JSON.stringify(currentUser));
+            window.location.href = 'dashboard.html';
+            return;
+        } catch (error) {
+            // Not signed in; stay on the login page
+        }
+    }
+
     if (token && (currentPage.endsWith('/login.html') || currentPage.endsWith('/signup.html'))) {
         // Redirect to dashboard if already logged in
         if (user) {
@@ -56,3 +68,22 @@ document.addEventListener('DOMContentLoaded', async () => {
     }
 });
 
+
+// Show the server's password policy on a password field. The server checks it again on submit,
+// so a failed fetch just leaves the default hint in place.
+async function showPasswordPolicy(input, hint) {
+    try {
+        const policy = await API.getPasswordPolicy();
+        input.minLength = policy.minLength;
+        input.maxLength = policy.maxLength;
+
+        const classNames = { lower: 'a lowercase letter', upper: 'an uppercase letter', digit: 'a digit', symbol: 'a symbol' };
+        const parts = [`At least ${policy.minLength} characters`];
+        if (policy.requiredClasses.length > 0) {
+            parts.push('including ' + policy.requiredClasses.map(c => classNames[c]).join(', '));
+        }
+        hint.textContent = parts.join(', ') + (policy.breachCheck ? '. Passwords found in known data breaches are not accepted.' : '.');
+    } catch (error) {
+        console.error('Could not load password policy:', error);
+    }
+}
@@ -9,6 +9,7 @@ document.addEventListener('DOMContentLoaded', async () => {
     }
 
     const user = JSON.parse(userStr);
+    refreshVerificationNotice();
     const urlParams = new URLSearchParams(window.location.search);
     const role = urlParams.get('role') || user.role.toLowerCase();
 
@@ -94,7 +95,12 @@ function displayStadiums(stadiums) {
                 <div>
                     <h4>${stadium.name}</h4>
                     <p><strong>Location:</strong> ${stadium.location}</p>
+                    <p><strong>Status:</strong> ${formatStadiumStatus(stadium.status)}</p>
+                    ${stadium.status === 'Rejected' && stadium.reviewNote ? `<p><strong>Review note:</strong> ${stadium.reviewNote}</p>` : ''}
                 </div>
+                ${stadium.status === 'Draft' || stadium.status === 'Rejected' ? `
+                    <button class="btn btn-primary" onclick="submitStadiumForReview(${stadium.stadiumId})">Submit for Review</button>
+                ` : ''}
             </div>
             <div class="arena-table-container" id="arena-container-${stadium.stadiumId}">
                 <div class="arena-table-header">
@@ -116,6 +122,25 @@ function displayStadiums(stadiums) {
     });
 }
 
+function formatStadiumStatus(status) {
+    const labels = {
+        Draft: 'Draft (not visible to customers)',
+        PendingReview: 'Pending review',
+        Approved: 'Approved',
+        Rejected: 'Rejected',
+    };
+    return labels[status] || status;
+}
+
+async function submitStadiumForReview(stadiumId) {
+    try {
+        await API.submitStadium(stadiumId);
+        loadOwnerDashboard();
+    } catch (error) {
+        alert('Error: ' + error.message);
+    }
+}
+
 // Stadium arena state for pagination, search, and sorting
 const stadiumArenaState = {};
 
@@ -652,3 +677,22 @@ window.onclick = function(event) {
     }
 }
 
+// Show the verify-email notice from fresh account data, since the stored user goes stale once the link is opened
+async function refreshVerificationNotice() {
+    try {
+        const user = await API.getCurrentUser();
+        sessionStorage.setItem('user', JSON.stringify(user));
+        document.getElementById('verifyEmailNotice').style.display = user.emailVerified ? 'none' : 'block';
+    } catch (error) {
+        console.error('Error loading account:', error);
+    }
+}
+
+async function resendVerificationEmail() {
+    try {
+        await API.resendVerificationEmail();
+        alert('Verification email sent');
+    } catch (error) {
+        alert('Error: ' + error.message);
+    }
+}
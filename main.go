@@ -1,38 +1,121 @@
 package main
 
 import (
+	"BookMyArena/backend/cache"
 	"BookMyArena/backend/config"
+	"BookMyArena/backend/jobs"
+	"BookMyArena/backend/mail"
 	"BookMyArena/backend/routes"
 	"BookMyArena/backend/services"
+	"context"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
 func main() {
-	// Initialize database
-	config.InitDB()
-	defer config.CloseDB()
+	// Load settings from the config file, environment and flags
+	settings, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize database. If it can't be reached the server starts degraded and keeps retrying.
+	config.InitDB(settings.Database)
+	if !config.DBAvailable() {
+		jobs.Go("database reconnect", func() {
+			config.ReconnectDB(jobs.Done())
+		})
+	}
+
+	// Initialize outgoing mail
+	if err := mail.Init(settings.Mail, config.DB); err != nil {
+		log.Fatal(err)
+	}
+
+	// Pick the session and user cache
+	if err := cache.Init(settings.Cache); err != nil {
+		log.Fatal(err)
+	}
+
+	// Apply session, password, booking and login provider settings
+	deps, err := services.NewDeps(settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+	services.InitOIDC(deps, settings.OIDC)
 
 	// Clean expired sessions periodically
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-		for range ticker.C {
-			services.CleanExpiredSessions()
-		}
-	}()
+	jobs.Every("expired row cleanup", settings.Server.CleanupInterval, func() {
+		services.CleanExpiredSessions()
+		services.CleanExpiredOIDCStates()
+		services.CleanExpiredLoginThrottles()
+	})
+	jobs.Start()
 
 	// Setup routes
-	router := routes.SetupRoutes()
+	router := routes.SetupRoutes(settings, deps)
+
+	server := &http.Server{
+		Addr:              settings.Server.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: settings.Server.ReadHeaderTimeout,
+		ReadTimeout:       settings.Server.ReadTimeout,
+		WriteTimeout:      settings.Server.WriteTimeout,
+		IdleTimeout:       settings.Server.IdleTimeout,
+	}
+
+	// Run until SIGINT or SIGTERM; a second signal kills the process without waiting
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
 	// Start server
-	port := ":8080"
-	log.Printf("Server starting on port %s\n", port)
-	log.Println("BookMyArena API is ready!")
-	
-	if err := http.ListenAndServe(port, router); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on %s\n", settings.Server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+	if config.DBAvailable() {
+		log.Println("BookMyArena API is ready!")
+	} else {
+		log.Println("BookMyArena API is running without a database; /readyz reports not ready until it connects")
+	}
+
+	select {
+	case err := <-serverErr:
+		stop()
+		shutdown(server, settings.Server.ShutdownTimeout)
 		log.Fatal("Server failed to start:", err)
+	case <-ctx.Done():
+		stop()
+		log.Println("Shutting down...")
 	}
+
+	shutdown(server, settings.Server.ShutdownTimeout)
+	log.Println("Server stopped")
 }
 
+// shutdown stops accepting connections and lets in-flight requests finish, then stops background
+// jobs, and only then closes the cache and database they use. Everything shares one deadline.
+func shutdown(server *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Error draining HTTP requests:", err)
+	}
+	if err := jobs.Shutdown(ctx); err != nil {
+		log.Println("Error waiting for background jobs:", err)
+	}
+	if err := cache.Close(); err != nil {
+		log.Println("Error closing cache:", err)
+	}
+	config.CloseDB()
+}